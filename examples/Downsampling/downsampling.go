@@ -6,8 +6,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/apache/arrow-go/v18/arrow"
-
 	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
 )
 
@@ -85,41 +83,28 @@ func main() {
   `
 
 	//
-	// Execute the query and process the data.
+	// Execute the query once, print the downsampled data and write it back
+	// to the 'stat_downsampled' table.
 	//
-
-	iterator, err := client.Query(context.Background(), query)
+	pipeline := &influxdb3.Pipeline{
+		Source: client,
+		Query:  query,
+		Mapping: influxdb3.PipelineMapping{
+			Measurement: "stat_downsampled",
+			TimeColumn:  "window_start",
+		},
+		Transform: func(point *influxdb3.Point) *influxdb3.Point {
+			location, _ := point.GetTag("location")
+			avgValue := point.GetDoubleField("avg")
+			maxValue := point.GetDoubleField("max")
+			fmt.Printf("%s %s temperature: avg %.2f, max %.2f\n",
+				point.Values.Timestamp.Format(time.RFC822), location, *avgValue, *maxValue)
+			return point
+		},
+	}
+	written, err := pipeline.Run(context.Background())
 	if err != nil {
 		panic(err)
 	}
-
-	// Process the data as PointValues that can be used to write downsampled data back to the database.
-	for iterator.Next() {
-		row := iterator.AsPoints()
-		timestamp := (row.GetField("window_start").(arrow.Timestamp)).ToTime(arrow.Nanosecond)
-		location, _ := row.GetTag("location")
-		avgValue := row.GetDoubleField("avg")
-		maxValue := row.GetDoubleField("max")
-		fmt.Printf("%s %s temperature: avg %.2f, max %.2f\n", timestamp.Format(time.RFC822), location, *avgValue, *maxValue)
-
-		//
-		// Write back downsampled data.
-		//
-
-		// Create a downsampled Point for the 'stat_downsampled' table.
-		downsampledPoint, err := row.AsPointWithMeasurement("stat_downsampled")
-		if err != nil {
-			panic(err)
-		}
-
-		downsampledPoint = downsampledPoint.
-			RemoveField("window_start").
-			SetTimestampWithEpoch(timestamp.UnixNano())
-
-		// Write the downsampled Point to the database.
-		err = client.WritePoints(context.Background(), []*influxdb3.Point{downsampledPoint})
-		if err != nil {
-			panic(err)
-		}
-	}
+	fmt.Printf("%d downsampled points written\n", written)
 }
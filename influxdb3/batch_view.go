@@ -0,0 +1,215 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// BatchView gives typed access to the columns of a record batch without converting rows
+// to maps. The column accessors read the Arrow arrays directly and do not allocate per row.
+//
+// A BatchView and the columns returned by it are valid until the next call of
+// QueryIterator.NextBatch or QueryIterator.Close.
+type BatchView struct {
+	record arrow.RecordBatch
+}
+
+// NumRows returns the number of rows of the batch.
+func (b *BatchView) NumRows() int {
+	return int(b.record.NumRows())
+}
+
+// NumColumns returns the number of columns of the batch.
+func (b *BatchView) NumColumns() int {
+	return int(b.record.NumCols())
+}
+
+// ColumnName returns the name of the column at index col.
+func (b *BatchView) ColumnName(col int) string {
+	return b.record.ColumnName(col)
+}
+
+// ColumnIndex returns the index of the column with the given name, or -1 if there is no such column.
+func (b *BatchView) ColumnIndex(name string) int {
+	if indices := b.record.Schema().FieldIndices(name); len(indices) > 0 {
+		return indices[0]
+	}
+	return -1
+}
+
+// IsNull reports whether the value of column col at row is NULL.
+func (b *BatchView) IsNull(col, row int) bool {
+	return b.record.Column(col).IsNull(row)
+}
+
+// Record returns the underlying record batch.
+func (b *BatchView) Record() arrow.RecordBatch { //nolint:ireturn
+	return b.record
+}
+
+// Float64 returns the column col as float64 values.
+// It fails when the column is not a Float64 column.
+func (b *BatchView) Float64(col int) (ColumnView[float64], error) {
+	if arr, ok := b.record.Column(col).(*array.Float64); ok {
+		return ColumnView[float64]{arr: arr}, nil
+	}
+	return ColumnView[float64]{}, b.typeError(col, "float64")
+}
+
+// Int64 returns the column col as int64 values.
+// It fails when the column is not an Int64 column.
+func (b *BatchView) Int64(col int) (ColumnView[int64], error) {
+	if arr, ok := b.record.Column(col).(*array.Int64); ok {
+		return ColumnView[int64]{arr: arr}, nil
+	}
+	return ColumnView[int64]{}, b.typeError(col, "int64")
+}
+
+// Uint64 returns the column col as uint64 values.
+// It fails when the column is not a Uint64 column.
+func (b *BatchView) Uint64(col int) (ColumnView[uint64], error) {
+	if arr, ok := b.record.Column(col).(*array.Uint64); ok {
+		return ColumnView[uint64]{arr: arr}, nil
+	}
+	return ColumnView[uint64]{}, b.typeError(col, "uint64")
+}
+
+// Bool returns the column col as bool values.
+// It fails when the column is not a Boolean column.
+func (b *BatchView) Bool(col int) (ColumnView[bool], error) {
+	if arr, ok := b.record.Column(col).(*array.Boolean); ok {
+		return ColumnView[bool]{arr: arr}, nil
+	}
+	return ColumnView[bool]{}, b.typeError(col, "bool")
+}
+
+// String returns the column col as string values. String, LargeString and dictionary
+// encoded string columns are supported. The returned strings share the memory of the record,
+// copy them to keep them after the batch is released.
+func (b *BatchView) String(col int) (ColumnView[string], error) {
+	switch arr := b.record.Column(col).(type) {
+	case *array.String:
+		return ColumnView[string]{arr: arr}, nil
+	case *array.LargeString:
+		return ColumnView[string]{arr: arr}, nil
+	case *array.Dictionary:
+		if values, ok := arr.Dictionary().(*array.String); ok {
+			return ColumnView[string]{arr: dictionaryStrings{Dictionary: arr, values: values}}, nil
+		}
+	}
+	return ColumnView[string]{}, b.typeError(col, "string")
+}
+
+// Time returns the column col as time.Time values.
+// It fails when the column is not a Timestamp column.
+func (b *BatchView) Time(col int) (ColumnView[time.Time], error) {
+	if arr, ok := b.record.Column(col).(*array.Timestamp); ok {
+		unit := arr.DataType().(*arrow.TimestampType).Unit
+		return ColumnView[time.Time]{arr: timestamps{Timestamp: arr, unit: unit}}, nil
+	}
+	return ColumnView[time.Time]{}, b.typeError(col, "time")
+}
+
+func (b *BatchView) typeError(col int, expected string) error {
+	return fmt.Errorf("column '%s' of type %s cannot be read as %s",
+		b.record.ColumnName(col), b.record.Column(col).DataType(), expected)
+}
+
+// valueArray is an Arrow array with values of type T.
+type valueArray[T any] interface {
+	arrow.Array
+	Value(i int) T
+}
+
+// ColumnView is a typed view of a column of a BatchView.
+type ColumnView[T any] struct {
+	arr valueArray[T]
+}
+
+// Len returns the number of values of the column.
+func (c ColumnView[T]) Len() int {
+	return c.arr.Len()
+}
+
+// Value returns the value at row. The zero value is returned for NULL values,
+// use IsNull to distinguish them.
+func (c ColumnView[T]) Value(row int) T {
+	if c.arr.IsNull(row) {
+		var zero T
+		return zero
+	}
+	return c.arr.Value(row)
+}
+
+// IsNull reports whether the value at row is NULL.
+func (c ColumnView[T]) IsNull(row int) bool {
+	return c.arr.IsNull(row)
+}
+
+// NullN returns the number of NULL values of the column.
+func (c ColumnView[T]) NullN() int {
+	return c.arr.NullN()
+}
+
+// NullBitmap returns the Arrow validity bitmap of the column, a bit set to 1 marks a valid value.
+// It is nil when the column has no NULL values. The bitmap starts at bit offset Array().Data().Offset().
+func (c ColumnView[T]) NullBitmap() []byte {
+	return c.arr.NullBitmapBytes()
+}
+
+// Array returns the underlying Arrow array.
+func (c ColumnView[T]) Array() arrow.Array { //nolint:ireturn
+	switch arr := any(c.arr).(type) {
+	case dictionaryStrings:
+		return arr.Dictionary
+	case timestamps:
+		return arr.Timestamp
+	default:
+		return c.arr
+	}
+}
+
+// dictionaryStrings reads a dictionary encoded string array.
+type dictionaryStrings struct {
+	*array.Dictionary
+	values *array.String
+}
+
+func (a dictionaryStrings) Value(i int) string {
+	return a.values.Value(a.GetValueIndex(i))
+}
+
+// timestamps reads a timestamp array as time.Time.
+type timestamps struct {
+	*array.Timestamp
+	unit arrow.TimeUnit
+}
+
+func (a timestamps) Value(i int) time.Time {
+	return a.Timestamp.Value(i).ToTime(a.unit)
+}
@@ -0,0 +1,148 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchRecord(t *testing.T) arrow.RecordBatch {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "host", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}},
+		{Name: "region", Type: arrow.BinaryTypes.String},
+		{Name: "usage", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "count", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "total", Type: arrow.PrimitiveTypes.Uint64},
+		{Name: "ok", Type: arrow.FixedWidthTypes.Boolean},
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+	}, nil)
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+	hosts := rb.Field(0).(*array.BinaryDictionaryBuilder)
+	for _, h := range []string{"a", "b", "a"} {
+		require.NoError(t, hosts.AppendString(h))
+	}
+	rb.Field(1).(*array.StringBuilder).AppendValues([]string{"us", "eu", "us"}, nil)
+	rb.Field(2).(*array.Float64Builder).AppendValues([]float64{1.5, 0, 3.5}, []bool{true, false, true})
+	rb.Field(3).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	rb.Field(4).(*array.Uint64Builder).AppendValues([]uint64{10, 20, 30}, nil)
+	rb.Field(5).(*array.BooleanBuilder).AppendValues([]bool{true, false, true}, nil)
+	rb.Field(6).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{1000, 2000, 3000}, nil)
+	return rb.NewRecord()
+}
+
+func TestQueryIteratorNextBatch(t *testing.T) {
+	rec := batchRecord(t)
+	defer rec.Release()
+
+	it := NewQueryIteratorFromReader(&recordsReader{records: []arrow.RecordBatch{rec, rec}})
+	batches := 0
+	for batch := it.NextBatch(); batch != nil; batch = it.NextBatch() {
+		batches++
+		assert.Equal(t, 3, batch.NumRows())
+		assert.Equal(t, 7, batch.NumColumns())
+		assert.Equal(t, "usage", batch.ColumnName(2))
+		assert.Equal(t, 2, batch.ColumnIndex("usage"))
+		assert.Equal(t, -1, batch.ColumnIndex("missing"))
+
+		host, err := batch.String(batch.ColumnIndex("host"))
+		require.NoError(t, err)
+		region, err := batch.String(batch.ColumnIndex("region"))
+		require.NoError(t, err)
+		usage, err := batch.Float64(batch.ColumnIndex("usage"))
+		require.NoError(t, err)
+		count, err := batch.Int64(batch.ColumnIndex("count"))
+		require.NoError(t, err)
+		total, err := batch.Uint64(batch.ColumnIndex("total"))
+		require.NoError(t, err)
+		ok, err := batch.Bool(batch.ColumnIndex("ok"))
+		require.NoError(t, err)
+		ts, err := batch.Time(batch.ColumnIndex("time"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"a", "b", "a"}, []string{host.Value(0), host.Value(1), host.Value(2)})
+		assert.Equal(t, "eu", region.Value(1))
+		assert.InDelta(t, 1.5, usage.Value(0), 0)
+		assert.True(t, usage.IsNull(1))
+		assert.True(t, batch.IsNull(2, 1))
+		assert.InDelta(t, 0.0, usage.Value(1), 0)
+		assert.Equal(t, 1, usage.NullN())
+		assert.NotNil(t, usage.NullBitmap())
+		assert.Equal(t, int64(3), count.Value(2))
+		assert.Equal(t, uint64(20), total.Value(1))
+		assert.False(t, ok.Value(1))
+		assert.Equal(t, time.UnixMilli(2000).UTC(), ts.Value(1).UTC())
+		assert.Equal(t, 3, ts.Len())
+		assert.Same(t, rec.Column(6), ts.Array())
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 2, batches)
+	assert.Nil(t, it.NextBatch())
+}
+
+func TestBatchViewTypeMismatch(t *testing.T) {
+	rec := batchRecord(t)
+	defer rec.Release()
+
+	batch := &BatchView{record: rec}
+	_, err := batch.Int64(batch.ColumnIndex("usage"))
+	require.EqualError(t, err, "column 'usage' of type float64 cannot be read as int64")
+	_, err = batch.Time(batch.ColumnIndex("count"))
+	require.Error(t, err)
+	_, err = batch.String(batch.ColumnIndex("ok"))
+	require.Error(t, err)
+}
+
+func TestBatchViewNoAllocations(t *testing.T) {
+	rec := batchRecord(t)
+	defer rec.Release()
+
+	batch := &BatchView{record: rec}
+	usage, err := batch.Float64(2)
+	require.NoError(t, err)
+	host, err := batch.String(0)
+	require.NoError(t, err)
+	ts, err := batch.Time(6)
+	require.NoError(t, err)
+
+	var sum float64
+	var n int
+	allocs := testing.AllocsPerRun(100, func() {
+		for row := range batch.NumRows() {
+			sum += usage.Value(row)
+			n += len(host.Value(row))
+			n += ts.Value(row).Second()
+		}
+	})
+	assert.Zero(t, allocs)
+	assert.Positive(t, n)
+	assert.Positive(t, sum)
+}
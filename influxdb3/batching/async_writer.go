@@ -0,0 +1,272 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package batching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+)
+
+// DefaultFlushInterval is the default interval after which AsyncWriter writes a partial batch
+const DefaultFlushInterval = time.Second
+
+// DefaultQueueSize is the default number of batches AsyncWriter queues for writing
+const DefaultQueueSize = 10
+
+// ErrAsyncWriterClosed is returned when points are written to a closed AsyncWriter
+var ErrAsyncWriterClosed = errors.New("async writer closed")
+
+// AsyncWriteError is reported by AsyncWriter when writing a batch fails.
+// It holds the points of the failed batch so that they can be retried or logged.
+type AsyncWriteError struct {
+	// Err is the error returned by the client
+	Err error
+	// Points of the failed batch
+	Points []*influxdb3.Point
+}
+
+// Error implements Error interface
+func (e *AsyncWriteError) Error() string {
+	return fmt.Sprintf("async write of %d points: %v", len(e.Points), e.Err)
+}
+
+// Unwrap returns the error returned by the client
+func (e *AsyncWriteError) Unwrap() error {
+	return e.Err
+}
+
+type AsyncOption func(*AsyncWriter)
+
+// WithAsyncBatcherOptions configures the Batcher owned by the AsyncWriter,
+// e.g. WithSize. Emit callbacks and the flush interval are set by the AsyncWriter.
+func WithAsyncBatcherOptions(options ...Option) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.batcherOptions = append(w.batcherOptions, options...)
+	}
+}
+
+// WithAsyncWriteOptions sets the write options used for each batch, e.g. influxdb3.WithDatabase
+func WithAsyncWriteOptions(options ...influxdb3.WriteOption) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.writeOptions = append(w.writeOptions, options...)
+	}
+}
+
+// WithAsyncFlushInterval changes the interval after which a partial batch is written.
+// A zero or negative value disables time-based flushing.
+func WithAsyncFlushInterval(interval time.Duration) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.flushInterval = interval
+	}
+}
+
+// WithAsyncQueueSize changes the number of batches waiting to be written.
+// When the queue is full, Write blocks until a batch is written.
+func WithAsyncQueueSize(size int) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.queueSize = size
+	}
+}
+
+// WithAsyncErrorCallback sets the function called when writing a batch fails.
+// The callback is called from the background goroutine, so please return as fast as possible.
+// When set, errors are not sent to the Errors channel.
+func WithAsyncErrorCallback(f func(*AsyncWriteError)) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.errorCallback = f
+	}
+}
+
+// AsyncWriter writes points in the background. Points are collected by
+// a Batcher and written using the client when a batch is full or when
+// the flush interval elapses. Failures are reported to the error callback,
+// or to the Errors channel when no callback is set.
+//
+// Close must be called to write the remaining points and stop the background goroutines.
+type AsyncWriter struct {
+	client         *influxdb3.Client
+	batcher        *Batcher
+	batcherOptions []Option
+	writeOptions   []influxdb3.WriteOption
+	flushInterval  time.Duration
+	queueSize      int
+	errorCallback  func(*AsyncWriteError)
+
+	queue  chan []*influxdb3.Point
+	errors chan error
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+	// guards closed
+	mu       sync.RWMutex
+	writerWg sync.WaitGroup
+	// set when a batch is dropped or its write is canceled by Close
+	interrupted atomic.Bool
+}
+
+// NewAsyncWriter creates an AsyncWriter writing through the given client and starts
+// its background goroutines. By default, batches of DefaultBatchSize points are written,
+// partial batches are written after DefaultFlushInterval and up to DefaultQueueSize
+// batches wait for writing.
+func NewAsyncWriter(client *influxdb3.Client, options ...AsyncOption) *AsyncWriter {
+	w := &AsyncWriter{
+		client:        client,
+		flushInterval: DefaultFlushInterval,
+		queueSize:     DefaultQueueSize,
+	}
+
+	// Apply the options
+	for _, o := range options {
+		o(w)
+	}
+
+	// setup internal data
+	w.queue = make(chan []*influxdb3.Point, max(w.queueSize, 0))
+	w.errors = make(chan error, max(w.queueSize, 1))
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.batcher = NewBatcher(append(slices.Clone(w.batcherOptions),
+		WithFlushInterval(w.flushInterval), WithEmitCallback(w.enqueue))...)
+
+	w.writerWg.Add(1)
+	go w.writeLoop()
+	w.batcher.Start()
+
+	return w
+}
+
+// Write adds points to the current batch. It returns without waiting
+// for the points to be written, unless the queue of batches is full.
+func (w *AsyncWriter) Write(points ...*influxdb3.Point) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrAsyncWriterClosed
+	}
+	w.batcher.Add(points...)
+	return nil
+}
+
+// Flush queues the current partial batch for writing without waiting for it to be written.
+func (w *AsyncWriter) Flush() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	w.flush()
+}
+
+// Errors returns the channel receiving write failures as *AsyncWriteError
+// when no error callback is set. Errors are dropped when the channel is full.
+// The channel is closed by Close.
+func (w *AsyncWriter) Errors() <-chan error {
+	return w.errors
+}
+
+// Close writes the remaining points and stops the background goroutines.
+// When ctx is done before all batches are written, the pending writes are canceled,
+// the batches not yet written are dropped and ctx.Err() is returned. Subsequent calls return nil.
+func (w *AsyncWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	// A done ctx cancels the pending writes and the batches waiting for the queue
+	stop := context.AfterFunc(ctx, w.cancel)
+
+	// No more points can be added, write what remains
+	w.batcher.Close()
+	close(w.queue)
+	w.writerWg.Wait()
+	stop()
+
+	// ctx is done also when it expires after all batches are written
+	var err error
+	if w.interrupted.Load() {
+		err = ctx.Err()
+	}
+	w.cancel()
+	close(w.errors)
+	return err
+}
+
+// flush enqueues the remaining points of the batcher.
+func (w *AsyncWriter) flush() {
+	for {
+		points := w.batcher.Emit()
+		if len(points) == 0 {
+			return
+		}
+		w.enqueue(points)
+	}
+}
+
+// enqueue sends a copy of the batch to the writer goroutine.
+// The batch is dropped when the writer is canceled by Close.
+func (w *AsyncWriter) enqueue(points []*influxdb3.Point) {
+	if len(points) == 0 {
+		return
+	}
+	select {
+	case w.queue <- slices.Clone(points):
+	case <-w.ctx.Done():
+		w.interrupted.Store(true)
+	}
+}
+
+func (w *AsyncWriter) writeLoop() {
+	defer w.writerWg.Done()
+	for points := range w.queue {
+		err := w.client.WritePoints(w.ctx, points, w.writeOptions...)
+		if err != nil {
+			if w.ctx.Err() != nil {
+				w.interrupted.Store(true)
+			}
+			w.reportError(&AsyncWriteError{Err: err, Points: points})
+		}
+	}
+}
+
+func (w *AsyncWriter) reportError(err *AsyncWriteError) {
+	if w.errorCallback != nil {
+		w.errorCallback(err)
+		return
+	}
+	select {
+	case w.errors <- err:
+	default:
+		slog.Warn(fmt.Sprintf("AsyncWriter error channel is full, dropping error: %v", err))
+	}
+}
@@ -0,0 +1,263 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package batching
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type writeRecorder struct {
+	sync.Mutex
+	requests []int // number of lines of each request
+	status   int
+}
+
+func (wr *writeRecorder) handler(w http.ResponseWriter, r *http.Request) {
+	// initialization of query client
+	if r.Method == "PRI" {
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	wr.Lock()
+	wr.requests = append(wr.requests, bytes.Count(body, []byte{'\n'}))
+	status := wr.status
+	wr.Unlock()
+	if status == 0 {
+		status = http.StatusNoContent
+	}
+	w.WriteHeader(status)
+}
+
+func (wr *writeRecorder) counts() []int {
+	wr.Lock()
+	defer wr.Unlock()
+	return append([]int{}, wr.requests...)
+}
+
+func (wr *writeRecorder) lines() int {
+	wr.Lock()
+	defer wr.Unlock()
+	total := 0
+	for _, n := range wr.requests {
+		total += n
+	}
+	return total
+}
+
+func newTestClient(t *testing.T, wr *writeRecorder) *influxdb3.Client {
+	ts := httptest.NewServer(http.HandlerFunc(wr.handler))
+	t.Cleanup(ts.Close)
+	c, err := influxdb3.New(influxdb3.ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+		WriteOptions: &influxdb3.WriteOptions{
+			Precision: influxdb3.Nanosecond,
+			UseV2Api:  true,
+		},
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func testPoints(count int) []*influxdb3.Point {
+	points := make([]*influxdb3.Point, count)
+	for i := range points {
+		points[i] = influxdb3.NewPoint("test",
+			map[string]string{"foo": "bar"},
+			map[string]any{"count": i + 1},
+			time.Unix(int64(i), 0))
+	}
+	return points
+}
+
+func TestAsyncWriterBatches(t *testing.T) {
+	wr := &writeRecorder{}
+	w := NewAsyncWriter(newTestClient(t, wr),
+		WithAsyncBatcherOptions(WithSize(5)),
+		WithAsyncFlushInterval(0))
+
+	for _, p := range testPoints(12) {
+		require.NoError(t, w.Write(p))
+	}
+	require.NoError(t, w.Close(context.Background()))
+
+	assert.Equal(t, []int{5, 5, 2}, wr.counts())
+	assert.ErrorIs(t, w.Write(testPoints(1)...), ErrAsyncWriterClosed)
+	assert.NoError(t, w.Close(context.Background()))
+}
+
+func TestAsyncWriterFlushInterval(t *testing.T) {
+	wr := &writeRecorder{}
+	w := NewAsyncWriter(newTestClient(t, wr),
+		WithAsyncBatcherOptions(WithSize(100)),
+		WithAsyncFlushInterval(20*time.Millisecond))
+	defer w.Close(context.Background())
+
+	require.NoError(t, w.Write(testPoints(3)...))
+	assert.Eventually(t, func() bool {
+		return wr.lines() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAsyncWriterFlush(t *testing.T) {
+	wr := &writeRecorder{}
+	w := NewAsyncWriter(newTestClient(t, wr),
+		WithAsyncBatcherOptions(WithSize(100)),
+		WithAsyncFlushInterval(0))
+	defer w.Close(context.Background())
+
+	require.NoError(t, w.Write(testPoints(4)...))
+	w.Flush()
+	assert.Eventually(t, func() bool {
+		return wr.lines() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAsyncWriterErrors(t *testing.T) {
+	wr := &writeRecorder{status: http.StatusBadRequest}
+	w := NewAsyncWriter(newTestClient(t, wr),
+		WithAsyncBatcherOptions(WithSize(2)),
+		WithAsyncFlushInterval(0))
+
+	points := testPoints(3)
+	require.NoError(t, w.Write(points...))
+	require.NoError(t, w.Close(context.Background()))
+
+	var errs []*AsyncWriteError
+	for err := range w.Errors() {
+		var asyncErr *AsyncWriteError
+		require.ErrorAs(t, err, &asyncErr)
+		errs = append(errs, asyncErr)
+	}
+	require.Len(t, errs, 2)
+	assert.Equal(t, points[:2], errs[0].Points)
+	assert.Equal(t, points[2:], errs[1].Points)
+	var svErr *influxdb3.ServerError
+	assert.ErrorAs(t, errs[0], &svErr)
+	assert.Equal(t, http.StatusBadRequest, svErr.StatusCode)
+}
+
+func TestAsyncWriterErrorCallback(t *testing.T) {
+	wr := &writeRecorder{status: http.StatusBadRequest}
+	var mu sync.Mutex
+	failed := 0
+	w := NewAsyncWriter(newTestClient(t, wr),
+		WithAsyncBatcherOptions(WithSize(2)),
+		WithAsyncErrorCallback(func(err *AsyncWriteError) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed += len(err.Points)
+		}))
+
+	require.NoError(t, w.Write(testPoints(5)...))
+	require.NoError(t, w.Close(context.Background()))
+
+	assert.Equal(t, 5, failed)
+	_, ok := <-w.Errors()
+	assert.False(t, ok)
+}
+
+func TestAsyncWriterCloseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	defer close(release)
+	c, err := influxdb3.New(influxdb3.ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	w := NewAsyncWriter(c)
+	require.NoError(t, w.Write(testPoints(1)...))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded)
+}
+
+func TestAsyncWriterCloseTimeoutFullQueue(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	defer close(release)
+	c, err := influxdb3.New(influxdb3.ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	// the first batch blocks the writer, the remaining point cannot be queued
+	w := NewAsyncWriter(c, WithAsyncQueueSize(0), WithAsyncBatcherOptions(WithSize(2)))
+	require.NoError(t, w.Write(testPoints(3)...))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestAsyncWriterCloseDoneContextDrained(t *testing.T) {
+	wr := &writeRecorder{}
+	w := NewAsyncWriter(newTestClient(t, wr), WithAsyncFlushInterval(0))
+
+	// nothing is left to write, the done context does not interrupt the drain
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, w.Close(ctx))
+	assert.Empty(t, wr.counts())
+}
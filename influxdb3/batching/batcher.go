@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
 )
@@ -59,6 +60,16 @@ type PointEmittable interface {
 
 type Option func(PointEmittable)
 
+// maxBytesSetter is implemented by batchers bounding batches by serialized size.
+type maxBytesSetter interface {
+	SetMaxBytes(maxBytes int, options *influxdb3.WriteOptions)
+}
+
+// flushIntervalSetter is implemented by batchers supporting time-based flushing.
+type flushIntervalSetter interface {
+	SetFlushInterval(interval time.Duration)
+}
+
 // WithSize changes the batch-size emitted by the batcher
 func WithSize(size int) Option {
 	return func(b PointEmittable) {
@@ -100,14 +111,46 @@ func WithEmitCallback(f func([]*influxdb3.Point)) Option {
 	}
 }
 
+// WithFlushInterval sets the maximum time points are kept in the batcher before
+// a partial batch is emitted through the emit callback. Time-based flushing is
+// active only between Start and Close. Zero (default) disables it.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(b PointEmittable) {
+		if f, ok := b.(flushIntervalSetter); ok {
+			f.SetFlushInterval(interval)
+		}
+	}
+}
+
+// WithMaxBytes bounds the batches emitted by the batcher by the size of the points
+// serialized to line protocol, in addition to the number of points. The size
+// is computed like Client.WritePoints does, using the precision, default tags and
+// tag order of the given write options, typically the client's WriteOptions.
+// A nil options uses influxdb3.DefaultWriteOptions. A single point larger than
+// maxBytes is emitted alone. Zero (default) disables the limit.
+func WithMaxBytes(maxBytes int, options *influxdb3.WriteOptions) Option {
+	return func(b PointEmittable) {
+		if s, ok := b.(maxBytesSetter); ok {
+			s.SetMaxBytes(maxBytes, options)
+		}
+	}
+}
+
 // Batcher collects points and emits them as batches
 type Batcher struct {
 	size            int
 	initialCapacity int
+	flushInterval   time.Duration
+	maxBytes        int
+	writeOptions    *influxdb3.WriteOptions
 	callbackReady   func()
 	callbackEmit    func([]*influxdb3.Point)
 
 	points []*influxdb3.Point
+	// serialized size of each point, tracked only with maxBytes set
+	sizes  []int
+	bytes  int
+	ticker flushTicker
 	sync.Mutex
 }
 
@@ -128,6 +171,9 @@ func NewBatcher(options ...Option) *Batcher {
 
 	// setup internal data
 	b.points = make([]*influxdb3.Point, 0, b.initialCapacity)
+	if b.maxBytes > 0 {
+		b.sizes = make([]int, 0, b.initialCapacity)
+	}
 
 	return b
 }
@@ -159,6 +205,54 @@ func (b *Batcher) SetEmitCallback(f func([]*influxdb3.Point)) {
 	b.callbackEmit = f
 }
 
+// SetMaxBytes sets the maximum serialized size of a batch in bytes and the write
+// options used to serialize the points.
+func (b *Batcher) SetMaxBytes(maxBytes int, options *influxdb3.WriteOptions) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.maxBytes = maxBytes
+	b.writeOptions = options
+
+	// recompute the sizes of points already added
+	b.sizes = b.sizes[:0]
+	b.bytes = 0
+	if b.maxBytes > 0 {
+		for _, p := range b.points {
+			size := b.pointSize(p)
+			b.sizes = append(b.sizes, size)
+			b.bytes += size
+		}
+	}
+}
+
+// SetFlushInterval sets the interval of time-based flushing.
+func (b *Batcher) SetFlushInterval(interval time.Duration) {
+	b.flushInterval = interval
+}
+
+// Start starts emitting partial batches every flush interval through the emit
+// callback. It does nothing when no flush interval is set or the batcher
+// is already started.
+func (b *Batcher) Start() {
+	b.ticker.start(b.flushInterval, func() {
+		b.Lock()
+		defer b.Unlock()
+		b.emitAll()
+	})
+}
+
+// Close stops time-based flushing and emits the remaining points through
+// the emit callback. Without the emit callback the points are left to be
+// collected with Emit or Flush.
+func (b *Batcher) Close() {
+	b.ticker.stop()
+
+	b.Lock()
+	defer b.Unlock()
+	b.emitAll()
+}
+
 // Add metric(s) to the batcher and call the given callbacks if any
 func (b *Batcher) Add(p ...*influxdb3.Point) {
 	b.Lock()
@@ -166,6 +260,13 @@ func (b *Batcher) Add(p ...*influxdb3.Point) {
 
 	// Add the point
 	b.points = append(b.points, p...)
+	if b.maxBytes > 0 {
+		for _, point := range p {
+			size := b.pointSize(point)
+			b.sizes = append(b.sizes, size)
+			b.bytes += size
+		}
+	}
 
 	// Call callbacks if a new batch is ready
 	for b.isReady() {
@@ -176,7 +277,7 @@ func (b *Batcher) Add(p ...*influxdb3.Point) {
 			// no emitter callback
 			slog.Debug(
 				fmt.Sprintf("Batcher load is %d points waiting to be emitted.",
-					b.CurrentLoadSize()),
+					len(b.points)),
 			)
 			break
 		}
@@ -194,12 +295,30 @@ func (b *Batcher) Ready() bool {
 // Flush drains all points even if the internal buffer is currently larger than size.
 // It does not call the callbackEmit method
 func (b *Batcher) Flush() []*influxdb3.Point {
+	b.Lock()
+	defer b.Unlock()
+
 	points := b.points
 	b.points = b.points[:0]
+	b.sizes = b.sizes[:0]
+	b.bytes = 0
 	return points
 }
 
+// CurrentLoadBytes returns the serialized size of the points waiting to be emitted.
+// It is tracked only when the batcher is bounded by WithMaxBytes.
+func (b *Batcher) CurrentLoadBytes() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.bytes
+}
+
+// CurrentLoadSize returns the number of points waiting to be emitted
 func (b *Batcher) CurrentLoadSize() int {
+	b.Lock()
+	defer b.Unlock()
+
 	return len(b.points)
 }
 
@@ -214,14 +333,48 @@ func (b *Batcher) Emit() []*influxdb3.Point {
 }
 
 func (b *Batcher) isReady() bool {
-	return len(b.points) >= b.size
+	return len(b.points) >= b.size || (b.maxBytes > 0 && len(b.points) > 0 && b.bytes >= b.maxBytes)
 }
 
 func (b *Batcher) emitPoints() []*influxdb3.Point {
 	l := min(b.size, len(b.points))
 
+	if b.maxBytes > 0 {
+		// keep the batch within maxBytes, but emit at least one point
+		bytes := 0
+		for i := range l {
+			if i > 0 && bytes+b.sizes[i] > b.maxBytes {
+				l = i
+				break
+			}
+			bytes += b.sizes[i]
+		}
+		b.sizes = b.sizes[l:]
+		b.bytes -= bytes
+	}
+
 	points := b.points[:l]
 	b.points = b.points[l:]
 
 	return points
 }
+
+// pointSize returns the size of the point serialized to line protocol. Points failing
+// to serialize count as empty, the error is reported when the batch is written.
+func (b *Batcher) pointSize(p *influxdb3.Point) int {
+	bts, err := p.MarshalBinaryWithOptions(b.writeOptions)
+	if err != nil {
+		slog.Debug(fmt.Sprintf("Batcher cannot compute point size: %v", err))
+		return 0
+	}
+	return len(bts)
+}
+
+func (b *Batcher) emitAll() {
+	if b.callbackEmit == nil {
+		return
+	}
+	for len(b.points) > 0 {
+		b.callbackEmit(b.emitPoints())
+	}
+}
@@ -23,6 +23,7 @@
 package batching
 
 import (
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -212,3 +213,131 @@ func TestFlush(t *testing.T) {
 	assert.Len(t, flushed, batchSize*loadFactor)
 	assert.Equal(t, 0, b.CurrentLoadSize())
 }
+
+func TestFlushInterval(t *testing.T) {
+	var mu sync.Mutex
+	emitted := make([][]*influxdb3.Point, 0)
+	b := NewBatcher(
+		WithSize(10),
+		WithFlushInterval(10*time.Millisecond),
+		WithEmitCallback(func(points []*influxdb3.Point) {
+			mu.Lock()
+			defer mu.Unlock()
+			emitted = append(emitted, append([]*influxdb3.Point(nil), points...))
+		}),
+	)
+	assert.Equal(t, 10*time.Millisecond, b.flushInterval)
+
+	b.Start()
+	b.Start() // no-op when already started
+	b.Add(&influxdb3.Point{}, &influxdb3.Point{}, &influxdb3.Point{})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(emitted) == 1
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	assert.Len(t, emitted[0], 3)
+	mu.Unlock()
+	assert.Equal(t, 0, b.CurrentLoadSize())
+	b.Close()
+}
+
+func TestClose(t *testing.T) {
+	emitted := make([]int, 0)
+	b := NewBatcher(
+		WithSize(5),
+		WithFlushInterval(time.Hour),
+		WithEmitCallback(func(points []*influxdb3.Point) {
+			emitted = append(emitted, len(points))
+		}),
+	)
+	b.Start()
+	b.Add(make([]*influxdb3.Point, 13)...)
+	assert.Equal(t, []int{5, 5}, emitted)
+
+	b.Close()
+	assert.Equal(t, []int{5, 5, 3}, emitted)
+	assert.Equal(t, 0, b.CurrentLoadSize())
+
+	b.Close() // safe to call again
+	assert.Equal(t, []int{5, 5, 3}, emitted)
+}
+
+func TestCloseWithoutEmitCallback(t *testing.T) {
+	b := NewBatcher(WithSize(5))
+	b.Start() // no flush interval, nothing started
+	b.Add(make([]*influxdb3.Point, 3)...)
+	b.Close()
+	assert.Len(t, b.Flush(), 3)
+}
+
+func TestMaxBytes(t *testing.T) {
+	options := &influxdb3.WriteOptions{
+		Precision:   influxdb3.Second,
+		DefaultTags: map[string]string{"host": "h1"},
+	}
+	newPoint := func(value string) *influxdb3.Point {
+		return influxdb3.NewPointWithMeasurement("m").
+			SetStringField("f", value).
+			SetTimestamp(time.Unix(1, 0))
+	}
+	// m,host=h1 f="x" 1\n
+	lineSize := len("m,host=h1 f=\"x\" 1\n")
+
+	emitted := make([][]*influxdb3.Point, 0)
+	b := NewBatcher(
+		WithSize(100),
+		WithMaxBytes(2*lineSize+1, options),
+		WithEmitCallback(func(points []*influxdb3.Point) {
+			emitted = append(emitted, points)
+		}),
+	)
+
+	b.Add(newPoint("x"), newPoint("x"))
+	assert.Empty(t, emitted)
+	assert.Equal(t, 2*lineSize, b.CurrentLoadBytes())
+
+	b.Add(newPoint("x"))
+	assert.Len(t, emitted, 1)
+	assert.Len(t, emitted[0], 2)
+	assert.Equal(t, 1, b.CurrentLoadSize())
+	assert.Equal(t, lineSize, b.CurrentLoadBytes())
+
+	// a point larger than the limit is emitted alone
+	large := newPoint(strings.Repeat("x", 3*lineSize))
+	b.Add(large)
+	assert.Len(t, emitted, 3)
+	assert.Len(t, emitted[1], 1)
+	assert.Equal(t, []*influxdb3.Point{large}, emitted[2])
+	assert.Equal(t, 0, b.CurrentLoadSize())
+	assert.Equal(t, 0, b.CurrentLoadBytes())
+
+	for _, batch := range emitted {
+		size := 0
+		for _, p := range batch {
+			bts, err := p.MarshalBinaryWithOptions(options)
+			assert.NoError(t, err)
+			size += len(bts)
+		}
+		assert.True(t, len(batch) == 1 || size <= 2*lineSize+1)
+	}
+}
+
+func TestMaxBytesWithSize(t *testing.T) {
+	emitted := make([]int, 0)
+	b := NewBatcher(
+		WithSize(2),
+		WithMaxBytes(1000, nil),
+		WithEmitCallback(func(points []*influxdb3.Point) {
+			emitted = append(emitted, len(points))
+		}),
+	)
+	for range 5 {
+		b.Add(influxdb3.NewPointWithMeasurement("m").SetIntegerField("f", 1))
+	}
+	assert.Equal(t, []int{2, 2}, emitted)
+	assert.Len(t, b.Flush(), 1)
+	assert.Equal(t, 0, b.CurrentLoadBytes())
+}
@@ -213,3 +213,77 @@ func Example_lineProtocol_batcher() {
 		log.Fatal(wErr)
 	}
 }
+
+func Example_asyncWriter() {
+	// Instantiate a client using your credentials.
+	client, err := influxdb3.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Close the client when finished and raise any errors.
+	defer client.Close()
+
+	// Create an AsyncWriter writing batches of 100 points, or whatever was collected each 5 seconds
+	w := batching.NewAsyncWriter(client,
+		batching.WithAsyncBatcherOptions(batching.WithSize(100)),
+		batching.WithAsyncFlushInterval(5*time.Second),
+		batching.WithAsyncErrorCallback(func(err *batching.AsyncWriteError) {
+			log.Printf("failed to write %d points: %v", len(err.Points), err.Err)
+		}),
+	)
+
+	// Write points without waiting for the server
+	for i := range 1000 {
+		p := influxdb3.NewPointWithMeasurement("stat").
+			SetTag("location", "Paris").
+			SetField("count", i)
+		if err := w.Write(p); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Write the remaining points and stop the background goroutines
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func Example_shardedWriter() {
+	// Instantiate a client using your credentials.
+	client, err := influxdb3.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Close the client when finished and raise any errors.
+	defer client.Close()
+
+	// Create a ShardedWriter writing with 8 workers, each batching 500 points
+	w := batching.NewShardedWriter(client,
+		batching.WithShardCount(8),
+		batching.WithShardOptions(batching.WithAsyncBatcherOptions(batching.WithSize(500))),
+		batching.WithShardErrorCallback(func(err *batching.AsyncWriteError) {
+			log.Printf("failed to write %d points: %v", len(err.Points), err.Err)
+		}),
+	)
+
+	// Points of the same series are written in order by the same worker
+	for i := range 100_000 {
+		p := influxdb3.NewPointWithMeasurement("stat").
+			SetTag("sensor", fmt.Sprintf("sensor-%d", i%100)).
+			SetField("count", i)
+		if err := w.Write(p); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Write the remaining points and stop the workers
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package batching
+
+import (
+	"sync"
+	"time"
+)
+
+// flushTicker runs a flush function periodically in a background goroutine.
+type flushTicker struct {
+	mu   sync.Mutex
+	quit chan struct{}
+	done chan struct{}
+}
+
+// start runs flush every interval until stop is called. It does nothing
+// when the interval is not positive or the ticker is already running.
+func (t *flushTicker) start(interval time.Duration, flush func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if interval <= 0 || t.quit != nil {
+		return
+	}
+	t.quit = make(chan struct{})
+	t.done = make(chan struct{})
+	go func(quit <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+				flush()
+			}
+		}
+	}(t.quit, t.done)
+}
+
+// stop stops the goroutine and waits for a running flush to finish.
+func (t *flushTicker) stop() {
+	t.mu.Lock()
+	quit, done := t.quit, t.done
+	t.quit, t.done = nil, nil
+	t.mu.Unlock()
+	if quit == nil {
+		return
+	}
+	close(quit)
+	<-done
+}
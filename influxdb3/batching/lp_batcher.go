@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 )
 
 const DefaultByteBatchSize = 100000
@@ -66,6 +67,17 @@ func WithEmitBytesCallback(f func([]byte)) LPOption {
 	}
 }
 
+// WithBufferFlushInterval sets the maximum time lines are kept in the batcher before
+// a partial batch is emitted through the emit bytes callback. Time-based flushing is
+// active only between Start and Close. Zero (default) disables it.
+func WithBufferFlushInterval(interval time.Duration) LPOption {
+	return func(b ByteEmittable) {
+		if f, ok := b.(flushIntervalSetter); ok {
+			f.SetFlushInterval(interval)
+		}
+	}
+}
+
 // LPBatcher collects line protocol strings storing them
 // to a byte buffer and then emitting them as []byte.
 //
@@ -86,11 +98,13 @@ func WithEmitBytesCallback(f func([]byte)) LPOption {
 type LPBatcher struct {
 	size            int
 	initialCapacity int
+	flushInterval   time.Duration
 
 	callbackReady    func()
 	callbackByteEmit func([]byte)
 
 	buffer []byte
+	ticker flushTicker
 	sync.Mutex
 }
 
@@ -140,6 +154,33 @@ func (lpb *LPBatcher) SetEmitBytesCallback(f func([]byte)) {
 	lpb.callbackByteEmit = f
 }
 
+// SetFlushInterval sets the interval of time-based flushing
+func (lpb *LPBatcher) SetFlushInterval(interval time.Duration) {
+	lpb.flushInterval = interval
+}
+
+// Start starts emitting partial batches every flush interval through the emit
+// bytes callback. It does nothing when no flush interval is set or the batcher
+// is already started.
+func (lpb *LPBatcher) Start() {
+	lpb.ticker.start(lpb.flushInterval, func() {
+		lpb.Lock()
+		defer lpb.Unlock()
+		lpb.emitAll()
+	})
+}
+
+// Close stops time-based flushing and emits the remaining bytes through
+// the emit bytes callback. Without the callback the bytes are left to be
+// collected with Emit or Flush.
+func (lpb *LPBatcher) Close() {
+	lpb.ticker.stop()
+
+	lpb.Lock()
+	defer lpb.Unlock()
+	lpb.emitAll()
+}
+
 // Add lines to the buffer and call appropriate callbacks when
 // the ready state is reached.
 func (lpb *LPBatcher) Add(lines ...string) {
@@ -163,7 +204,7 @@ func (lpb *LPBatcher) Add(lines ...string) {
 			// no emitter callback
 			slog.Debug(
 				fmt.Sprintf("Batcher load is %d bytes waiting to be emitted.",
-					lpb.CurrentLoadSize()),
+					len(lpb.buffer)),
 			)
 			break
 		}
@@ -191,6 +232,9 @@ func (lpb *LPBatcher) Emit() []byte {
 
 // Flush drains all bytes even if buffer currently larger than size
 func (lpb *LPBatcher) Flush() []byte {
+	lpb.Lock()
+	defer lpb.Unlock()
+
 	packet := lpb.buffer
 	lpb.buffer = lpb.buffer[:0]
 	return packet
@@ -198,6 +242,9 @@ func (lpb *LPBatcher) Flush() []byte {
 
 // CurrentLoadSize returns the current size of the internal buffer
 func (lpb *LPBatcher) CurrentLoadSize() int {
+	lpb.Lock()
+	defer lpb.Unlock()
+
 	return len(lpb.buffer)
 }
 
@@ -205,6 +252,15 @@ func (lpb *LPBatcher) isReady() bool {
 	return len(lpb.buffer) >= lpb.size
 }
 
+func (lpb *LPBatcher) emitAll() {
+	if lpb.callbackByteEmit == nil {
+		return
+	}
+	for len(lpb.buffer) > 0 {
+		lpb.callbackByteEmit(lpb.emitBytes())
+	}
+}
+
 func (lpb *LPBatcher) emitBytes() []byte {
 	firstLF := bytes.IndexByte(lpb.buffer, '\n')
 
@@ -218,7 +274,7 @@ func (lpb *LPBatcher) emitBytes() []byte {
 
 	// With first line larger than defined size
 	// just emit first line
-	if firstLF > lpb.size {
+	if firstLF >= lpb.size {
 		packet = lpb.buffer[:firstLF]
 		lpb.buffer = lpb.buffer[len(packet)+1:] // remove trailing '\n'
 		return packet
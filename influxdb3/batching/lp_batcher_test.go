@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -322,3 +323,70 @@ func TestLPAddLinesLargerThanSize(t *testing.T) {
 	assert.Equal(t, 2, emitCt, "Emit should be called correct number of times")
 	assert.Equal(t, strings.Join(linesWithCRLF, ""), string(resultBuffer))
 }
+
+func TestLPFlushInterval(t *testing.T) {
+	var mu sync.Mutex
+	emitted := make([]string, 0)
+	lpb := NewLPBatcher(
+		WithBufferSize(100),
+		WithBufferFlushInterval(10*time.Millisecond),
+		WithEmitBytesCallback(func(ba []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			emitted = append(emitted, string(ba))
+		}))
+	assert.Equal(t, 10*time.Millisecond, lpb.flushInterval)
+
+	lpb.Start()
+	lpb.Add("m f=1i", "m f=2i")
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(emitted) == 1
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, "m f=1i\nm f=2i\n", emitted[0])
+	mu.Unlock()
+	assert.Equal(t, 0, lpb.CurrentLoadSize())
+	lpb.Close()
+}
+
+func TestLPClose(t *testing.T) {
+	emitted := make([]string, 0)
+	lpb := NewLPBatcher(
+		WithBufferSize(14),
+		WithBufferFlushInterval(time.Hour),
+		WithEmitBytesCallback(func(ba []byte) {
+			emitted = append(emitted, string(ba))
+		}))
+	lpb.Start()
+	lpb.Add("m f=1i", "m f=2i", "m f=3i")
+	assert.Equal(t, []string{"m f=1i\nm f=2i\n"}, emitted)
+
+	lpb.Close()
+	assert.Equal(t, []string{"m f=1i\nm f=2i\n", "m f=3i\n"}, emitted)
+	assert.Equal(t, 0, lpb.CurrentLoadSize())
+}
+
+func TestLPAddLineOfSize(t *testing.T) {
+	emitted := make([]string, 0)
+	lpb := NewLPBatcher(
+		WithBufferSize(10),
+		WithEmitBytesCallback(func(ba []byte) {
+			emitted = append(emitted, string(ba))
+		}))
+	lpb.Add("0123456789")
+	assert.Equal(t, []string{"0123456789"}, emitted)
+	assert.Equal(t, 0, lpb.CurrentLoadSize())
+}
+
+func TestLPEmitFirstLineOfSize(t *testing.T) {
+	lpb := NewLPBatcher(WithBufferSize(10))
+	lpb.Add("0123456789", "abc", "def")
+
+	// the first line has the batch size without its LF, it is emitted alone
+	assert.Equal(t, "0123456789", string(lpb.Emit()))
+	assert.Equal(t, "abc\ndef\n", string(lpb.Emit()))
+	assert.Empty(t, lpb.Emit())
+}
@@ -0,0 +1,211 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package batching
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+)
+
+// DefaultShardCount is the default number of ShardedWriter workers
+const DefaultShardCount = 4
+
+type ShardedOption func(*ShardedWriter)
+
+// WithShardCount changes the number of workers writing in parallel
+func WithShardCount(count int) ShardedOption {
+	return func(w *ShardedWriter) {
+		w.shardCount = count
+	}
+}
+
+// WithShardOptions configures the AsyncWriter of each worker, e.g. WithAsyncBatcherOptions
+// or WithAsyncWriteOptions. Error callbacks are set by the ShardedWriter.
+func WithShardOptions(options ...AsyncOption) ShardedOption {
+	return func(w *ShardedWriter) {
+		w.shardOptions = append(w.shardOptions, options...)
+	}
+}
+
+// WithShardErrorCallback sets the function called when writing a batch of any worker fails.
+// The callback is called concurrently from the workers, so please return as fast as possible.
+// When set, errors are not sent to the Errors channel.
+func WithShardErrorCallback(f func(*AsyncWriteError)) ShardedOption {
+	return func(w *ShardedWriter) {
+		w.errorCallback = f
+	}
+}
+
+// ShardedWriter writes points in parallel using a number of workers. Points are assigned
+// to a worker by their series key, the measurement and the sorted tags, so all points of
+// a series are batched and written by the same worker in the order they were added.
+// Each worker is an AsyncWriter with its own batch and queue. Failures of all workers are
+// reported to the error callback, or to the Errors channel when no callback is set.
+//
+// Close must be called to write the remaining points and stop the workers.
+type ShardedWriter struct {
+	shardCount    int
+	shardOptions  []AsyncOption
+	errorCallback func(*AsyncWriteError)
+
+	shards []*AsyncWriter
+	errors chan error
+	closed bool
+	// guards closed
+	mu sync.RWMutex
+}
+
+// NewShardedWriter creates a ShardedWriter writing through the given client and starts its
+// workers. By default, DefaultShardCount workers are started, each configured with the
+// defaults of NewAsyncWriter.
+func NewShardedWriter(client *influxdb3.Client, options ...ShardedOption) *ShardedWriter {
+	w := &ShardedWriter{
+		shardCount: DefaultShardCount,
+	}
+
+	// Apply the options
+	for _, o := range options {
+		o(w)
+	}
+
+	// setup internal data
+	w.shardCount = max(w.shardCount, 1)
+	w.errors = make(chan error, w.shardCount*DefaultQueueSize)
+	shardOptions := append(slices.Clone(w.shardOptions), WithAsyncErrorCallback(w.reportError))
+	w.shards = make([]*AsyncWriter, w.shardCount)
+	for i := range w.shards {
+		w.shards[i] = NewAsyncWriter(client, shardOptions...)
+	}
+
+	return w
+}
+
+// Write adds points to the batches of the workers owning their series. It returns without
+// waiting for the points to be written, unless the queue of a worker is full.
+func (w *ShardedWriter) Write(points ...*influxdb3.Point) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrAsyncWriterClosed
+	}
+
+	if len(w.shards) == 1 {
+		return w.shards[0].Write(points...)
+	}
+	groups := make(map[int][]*influxdb3.Point)
+	for _, p := range points {
+		shard := w.shardOf(p)
+		groups[shard] = append(groups[shard], p)
+	}
+	for _, shard := range slices.Sorted(maps.Keys(groups)) {
+		if err := w.shards[shard].Write(groups[shard]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush queues the current partial batches of all workers for writing without waiting for them to be written.
+func (w *ShardedWriter) Flush() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	for _, shard := range w.shards {
+		shard.Flush()
+	}
+}
+
+// Errors returns the channel receiving write failures of all workers as *AsyncWriteError
+// when no error callback is set. Errors are dropped when the channel is full.
+// The channel is closed by Close.
+func (w *ShardedWriter) Errors() <-chan error {
+	return w.errors
+}
+
+// Close writes the remaining points of all workers and stops them. When ctx is done before
+// all batches are written, the pending writes are canceled and ctx.Err() is returned.
+// Subsequent calls return nil.
+func (w *ShardedWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	// Drain the workers in parallel
+	errs := make([]error, len(w.shards))
+	var wg sync.WaitGroup
+	for i, shard := range w.shards {
+		wg.Go(func() {
+			errs[i] = shard.Close(ctx)
+		})
+	}
+	wg.Wait()
+	close(w.errors)
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shardOf returns the index of the worker owning the series of the point.
+func (w *ShardedWriter) shardOf(p *influxdb3.Point) int {
+	if p == nil || p.Values == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(p.Values.MeasurementName))
+	for _, k := range slices.Sorted(maps.Keys(p.Values.Tags)) {
+		_, _ = h.Write([]byte{','})
+		_, _ = h.Write([]byte(k))
+		_, _ = h.Write([]byte{'='})
+		_, _ = h.Write([]byte(p.Values.Tags[k]))
+	}
+	return int(h.Sum64() % uint64(len(w.shards))) //nolint:gosec
+}
+
+func (w *ShardedWriter) reportError(err *AsyncWriteError) {
+	if w.errorCallback != nil {
+		w.errorCallback(err)
+		return
+	}
+	select {
+	case w.errors <- err:
+	default:
+		slog.Warn(fmt.Sprintf("ShardedWriter error channel is full, dropping error: %v", err))
+	}
+}
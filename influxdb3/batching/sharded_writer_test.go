@@ -0,0 +1,169 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package batching
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesRecorder records the values written for each series in the order of writing
+type seriesRecorder struct {
+	sync.Mutex
+	values map[string][]int
+}
+
+func (sr *seriesRecorder) handler(w http.ResponseWriter, r *http.Request) {
+	// initialization of query client
+	if r.Method == "PRI" {
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	sr.Lock()
+	defer sr.Unlock()
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		// test,series=s1 value=1i 1
+		parts := strings.Split(scanner.Text(), " ")
+		v, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(parts[1], "value="), "i"))
+		sr.values[parts[0]] = append(sr.values[parts[0]], v)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestShardedWriterKeepsSeriesOrder(t *testing.T) {
+	sr := &seriesRecorder{values: make(map[string][]int)}
+	ts := httptest.NewServer(http.HandlerFunc(sr.handler))
+	defer ts.Close()
+	c, err := influxdb3.New(influxdb3.ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	w := NewShardedWriter(c,
+		WithShardCount(4),
+		WithShardOptions(
+			WithAsyncBatcherOptions(WithSize(7)),
+			WithAsyncFlushInterval(0)))
+
+	series := 10
+	count := 50
+	for i := range count {
+		for s := range series {
+			p := influxdb3.NewPointWithMeasurement("test").
+				SetTag("series", fmt.Sprintf("s%d", s)).
+				SetIntegerField("value", int64(i)).
+				SetTimestamp(time.Unix(int64(i), 0))
+			require.NoError(t, w.Write(p))
+		}
+	}
+	require.NoError(t, w.Close(context.Background()))
+
+	sr.Lock()
+	defer sr.Unlock()
+	require.Len(t, sr.values, series)
+	for key, values := range sr.values {
+		require.Len(t, values, count, key)
+		for i, v := range values {
+			assert.Equal(t, i, v, key)
+		}
+	}
+	assert.ErrorIs(t, w.Write(testPoints(1)...), ErrAsyncWriterClosed)
+	assert.NoError(t, w.Close(context.Background()))
+}
+
+func TestShardedWriterShardOf(t *testing.T) {
+	w := &ShardedWriter{shards: make([]*AsyncWriter, 8)}
+	p1 := influxdb3.NewPoint("m", map[string]string{"a": "1", "b": "2"}, map[string]any{"f": 1}, time.Unix(1, 0))
+	p2 := influxdb3.NewPoint("m", map[string]string{"b": "2", "a": "1"}, map[string]any{"f": 2}, time.Unix(2, 0))
+	assert.Equal(t, w.shardOf(p1), w.shardOf(p2))
+
+	shards := make(map[int]bool)
+	for i := range 100 {
+		shards[w.shardOf(influxdb3.NewPoint("m", map[string]string{"id": strconv.Itoa(i)}, map[string]any{"f": 1}, time.Now()))] = true
+	}
+	assert.Greater(t, len(shards), 1)
+	assert.Equal(t, 0, w.shardOf(nil))
+}
+
+func TestShardedWriterErrors(t *testing.T) {
+	wr := &writeRecorder{status: http.StatusBadRequest}
+	w := NewShardedWriter(newTestClient(t, wr),
+		WithShardCount(3),
+		WithShardOptions(WithAsyncBatcherOptions(WithSize(2))))
+
+	points := make([]*influxdb3.Point, 0)
+	for i := range 9 {
+		points = append(points, influxdb3.NewPoint("test",
+			map[string]string{"id": strconv.Itoa(i)},
+			map[string]any{"count": i},
+			time.Unix(int64(i), 0)))
+	}
+	require.NoError(t, w.Write(points...))
+	require.NoError(t, w.Close(context.Background()))
+
+	failed := 0
+	for err := range w.Errors() {
+		var asyncErr *AsyncWriteError
+		require.ErrorAs(t, err, &asyncErr)
+		failed += len(asyncErr.Points)
+	}
+	assert.Equal(t, len(points), failed)
+}
+
+func TestShardedWriterErrorCallback(t *testing.T) {
+	wr := &writeRecorder{status: http.StatusBadRequest}
+	var mu sync.Mutex
+	failed := 0
+	w := NewShardedWriter(newTestClient(t, wr),
+		WithShardCount(2),
+		WithShardErrorCallback(func(err *AsyncWriteError) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed += len(err.Points)
+		}))
+
+	require.NoError(t, w.Write(testPoints(5)...))
+	w.Flush()
+	require.NoError(t, w.Close(context.Background()))
+
+	assert.Equal(t, 5, failed)
+	_, ok := <-w.Errors()
+	assert.False(t, ok)
+}
@@ -28,6 +28,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -42,6 +43,7 @@ import (
 	"time"
 
 	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
 )
 
 // Client implements an InfluxDB client.
@@ -54,6 +56,14 @@ type Client struct {
 	apiURL *url.URL
 	// Flight client for executing queries
 	queryClient flight.Client
+	// Flight clients of the locations of query endpoints
+	endpointClients endpointClients
+	// Allocator of received query results
+	allocator memory.Allocator
+	// Disk-backed storage of failed writes, nil if not configured
+	spool *spool
+	// Client-side throttling of writes, nil if not configured
+	rateLimiter *rateLimiter
 }
 
 // httpParams holds parameters for creating an HTTP request
@@ -68,6 +78,18 @@ type httpParams struct {
 	headers http.Header
 	// HTTP POST/PUT body
 	body io.Reader
+	// HTTP POST body in a pooled buffer, used instead of body when set
+	pooledBody *pooledBody
+	// Size of the uncompressed body counted by the write rate limit
+	size int
+}
+
+// release returns the pooled body to the pool when it is no longer read, the request cannot be sent anymore.
+func (p *httpParams) release() {
+	if p.pooledBody != nil {
+		p.pooledBody.release()
+		p.pooledBody = nil
+	}
 }
 
 // New creates new Client with given config, where `Host` and `Token` are mandatory.
@@ -79,7 +101,7 @@ func New(config ClientConfig) (*Client, error) {
 	}
 
 	// Create client instance
-	c := &Client{config: config}
+	c := &Client{config: config, allocator: memory.DefaultAllocator}
 
 	// Prepare host API URL
 	hostAddress := config.Host
@@ -149,12 +171,24 @@ func New(config ClientConfig) (*Client, error) {
 		c.config.WriteOptions = &options
 	}
 
+	c.rateLimiter = newRateLimiter(config.WriteRateLimit)
+
 	// Init FlightSQL client
 	err = c.initializeQueryClient(hostPortURL, secure, proxyURL)
 	if err != nil {
 		return nil, fmt.Errorf("flight client: %w", err)
 	}
 
+	// Open write spool (if configured)
+	if config.Spool != nil {
+		c.spool, err = openSpool(*config.Spool)
+		if err != nil {
+			_ = c.queryClient.Close()
+			return nil, err
+		}
+		c.startSpoolReplay()
+	}
+
 	return c, nil
 }
 
@@ -317,11 +351,14 @@ func (c *Client) GetServerVersion() (string, error) {
 	return v, nil
 }
 
-// Close closes all idle connections.
+// Close closes all idle connections and stops replaying of the write spool.
 func (c *Client) Close() error {
+	if c.spool != nil {
+		c.stopSpoolReplay()
+	}
 	c.config.HTTPClient.CloseIdleConnections()
 	err := c.queryClient.Close()
-	return err
+	return errors.Join(err, c.endpointClients.close())
 }
 
 // makeAPICall issues an HTTP request to InfluxDB host API url according to parameters.
@@ -334,10 +371,23 @@ func (c *Client) makeAPICall(ctx context.Context, params httpParams) (*http.Resp
 
 	fullURL := urlObj.String()
 
-	req, err := http.NewRequestWithContext(ctx, params.httpMethod, fullURL, params.body)
+	body := params.body
+	if params.pooledBody != nil {
+		body = params.pooledBody.reader()
+	}
+	req, err := http.NewRequestWithContext(ctx, params.httpMethod, fullURL, body)
 	if err != nil {
+		if params.pooledBody != nil {
+			_ = body.(io.Closer).Close()
+		}
 		return nil, fmt.Errorf("error calling %s: %w", fullURL, err)
 	}
+	if params.pooledBody != nil {
+		req.ContentLength = int64(params.pooledBody.Len())
+		req.GetBody = func() (io.ReadCloser, error) {
+			return params.pooledBody.reader(), nil
+		}
+	}
 	for k, v := range c.config.Headers {
 		for _, i := range v {
 			req.Header.Add(k, i)
@@ -412,6 +462,7 @@ func (c *Client) resolveHTTPError(r *http.Response) error {
 					return &PartialWriteError{
 						ServerError: httpError.ServerError,
 						LineErrors:  lineErrors,
+						summary:     httpError.Error,
 					}
 				}
 			}
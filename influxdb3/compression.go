@@ -0,0 +1,213 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionCodec is the content encoding used to compress write request bodies.
+type CompressionCodec string
+
+const (
+	// CompressionGzip compresses the body with gzip. It is the default codec.
+	CompressionGzip CompressionCodec = "gzip"
+	// CompressionZstd compresses the body with Zstandard.
+	CompressionZstd CompressionCodec = "zstd"
+)
+
+// Compression configures how write request bodies larger than
+// WriteOptions.GzipThreshold are compressed.
+type Compression struct {
+	// Codec used for compression. Default value: CompressionGzip
+	Codec CompressionCodec
+
+	// Level is the codec specific compression level; gzip levels from gzip.HuffmanOnly
+	// to gzip.BestCompression, where gzip.NoCompression is 0, or zstd levels from 1 to 22
+	// mapped to the nearest supported encoder level, where 0 is the default zstd level.
+	// Default value: nil, the default level of the codec.
+	Level *int
+}
+
+// level returns the compression level, defaultLevel when not set.
+func (c Compression) level(defaultLevel int) int {
+	if c.Level == nil {
+		return defaultLevel
+	}
+	return *c.Level
+}
+
+func (c Compression) validate() error {
+	switch c.Codec {
+	case "", CompressionGzip:
+		if level := c.level(gzip.DefaultCompression); level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			return fmt.Errorf("invalid gzip compression level: %d", level)
+		}
+	case CompressionZstd:
+		if level := c.level(0); level < 0 {
+			return fmt.Errorf("invalid zstd compression level: %d", level)
+		}
+	default:
+		return fmt.Errorf("unsupported compression codec: %q", c.Codec)
+	}
+	return nil
+}
+
+// contentEncoding returns the value of the Content-Encoding header for the codec.
+func (c Compression) contentEncoding() string {
+	if c.Codec == "" {
+		return string(CompressionGzip)
+	}
+	return string(c.Codec)
+}
+
+// compressBody compresses data with the configured codec reusing pooled encoders and
+// buffers. The buffer returns to the pool when the body is released and no longer read.
+func (c Compression) compressBody(data []byte) (*pooledBody, error) {
+	buf := compressionBufferPool.Get().(*bytes.Buffer)
+
+	switch c.Codec {
+	case CompressionZstd:
+		enc, err := zstdEncoder(c.level(0))
+		if err != nil {
+			putCompressionBuffer(buf)
+			return nil, err
+		}
+		buf.Write(enc.EncodeAll(data, buf.AvailableBuffer()))
+	default:
+		if err := compressWithGzip(buf, data, c.level(gzip.DefaultCompression)); err != nil {
+			putCompressionBuffer(buf)
+			return nil, err
+		}
+	}
+	return &pooledBody{buf: buf, refs: 1}, nil
+}
+
+var compressionBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func putCompressionBuffer(buf *bytes.Buffer) {
+	buf.Reset()
+	compressionBufferPool.Put(buf)
+}
+
+// pooledBody is a request body held in a pooled buffer. Each request attempt reads the body
+// by its own reader, as the HTTP transport may read the body even after the response is received.
+// The buffer returns to the pool when the body is released and all readers are closed.
+type pooledBody struct {
+	mu  sync.Mutex
+	buf *bytes.Buffer
+	// the owner and the open readers
+	refs int
+}
+
+// Len returns the size of the body.
+func (b *pooledBody) Len() int {
+	return b.buf.Len()
+}
+
+// reader returns a new reader of the body, the transport closes it when the request is sent.
+func (b *pooledBody) reader() io.ReadCloser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refs++
+	return &pooledBodyReader{Reader: bytes.NewReader(b.buf.Bytes()), body: b}
+}
+
+// release gives up the reference of the owner, reader must not be called anymore.
+func (b *pooledBody) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refs--
+	if b.refs == 0 {
+		putCompressionBuffer(b.buf)
+		b.buf = nil
+	}
+}
+
+type pooledBodyReader struct {
+	*bytes.Reader
+	body *pooledBody
+	once sync.Once
+}
+
+// Close releases the reference of the reader to the body.
+func (r *pooledBodyReader) Close() error {
+	r.once.Do(r.body.release)
+	return nil
+}
+
+// gzipWriterPools holds a *sync.Pool of *gzip.Writer per compression level.
+var gzipWriterPools sync.Map
+
+func compressWithGzip(buf *bytes.Buffer, data []byte, level int) error {
+	pool, _ := gzipWriterPools.LoadOrStore(level, &sync.Pool{})
+	gzipWriter, ok := pool.(*sync.Pool).Get().(*gzip.Writer)
+	if ok {
+		gzipWriter.Reset(buf)
+	} else {
+		var err error
+		if gzipWriter, err = gzip.NewWriterLevel(buf, level); err != nil {
+			return err
+		}
+	}
+	if _, err := gzipWriter.Write(data); err != nil {
+		_ = gzipWriter.Close()
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	pool.(*sync.Pool).Put(gzipWriter)
+	return nil
+}
+
+// zstdEncoders holds a shared *zstd.Encoder per encoder level. EncodeAll is safe
+// for concurrent use and reuses the encoder state internally.
+var zstdEncoders sync.Map
+
+func zstdEncoder(level int) (*zstd.Encoder, error) {
+	encoderLevel := zstd.SpeedDefault
+	if level > 0 {
+		encoderLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	if enc, ok := zstdEncoders.Load(encoderLevel); ok {
+		return enc.(*zstd.Encoder), nil
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel))
+	if err != nil {
+		return nil, err
+	}
+	actual, loaded := zstdEncoders.LoadOrStore(encoderLevel, enc)
+	if loaded {
+		_ = enc.Close()
+	}
+	return actual.(*zstd.Encoder), nil
+}
@@ -0,0 +1,197 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compressionLevel(level int) *int {
+	return &level
+}
+
+func decompressBody(t *testing.T, encoding string, r io.Reader) string {
+	t.Helper()
+	switch encoding {
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		require.NoError(t, err)
+		defer gr.Close()
+		b, err := io.ReadAll(gr)
+		require.NoError(t, err)
+		return string(b)
+	case "zstd":
+		zr, err := zstd.NewReader(r)
+		require.NoError(t, err)
+		defer zr.Close()
+		b, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		return string(b)
+	default:
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(b)
+	}
+}
+
+func TestCompressionCodecs(t *testing.T) {
+	data := []byte(strings.Repeat("cpu,host=h1 usage=0.5 1700000000000000000\n", 100))
+	tests := []struct {
+		compression Compression
+		encoding    string
+	}{
+		{Compression{}, "gzip"},
+		{Compression{Codec: CompressionGzip, Level: compressionLevel(gzip.BestSpeed)}, "gzip"},
+		{Compression{Codec: CompressionGzip, Level: compressionLevel(gzip.BestCompression)}, "gzip"},
+		{Compression{Codec: CompressionGzip, Level: compressionLevel(gzip.HuffmanOnly)}, "gzip"},
+		{Compression{Codec: CompressionZstd}, "zstd"},
+		{Compression{Codec: CompressionZstd, Level: compressionLevel(1)}, "zstd"},
+		{Compression{Codec: CompressionZstd, Level: compressionLevel(19)}, "zstd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			require.NoError(t, tt.compression.validate())
+			assert.Equal(t, tt.encoding, tt.compression.contentEncoding())
+			// pooled encoders and buffers are reused
+			for range 3 {
+				body, err := tt.compression.compressBody(data)
+				require.NoError(t, err)
+				assert.Less(t, body.Len(), len(data))
+				r := body.reader()
+				assert.Equal(t, string(data), decompressBody(t, tt.encoding, r))
+				require.NoError(t, r.Close())
+				body.release()
+			}
+		})
+	}
+}
+
+func TestCompressionGzipNoCompression(t *testing.T) {
+	data := []byte(strings.Repeat("cpu,host=h1 usage=0.5 1700000000000000000\n", 100))
+	compression := Compression{Level: compressionLevel(gzip.NoCompression)}
+	require.NoError(t, compression.validate())
+	body, err := compression.compressBody(data)
+	require.NoError(t, err)
+	defer body.release()
+
+	// stored blocks are not smaller than the data
+	assert.GreaterOrEqual(t, body.Len(), len(data))
+	r := body.reader()
+	defer r.Close()
+	assert.Equal(t, string(data), decompressBody(t, "gzip", r))
+}
+
+func TestPooledBodyRelease(t *testing.T) {
+	body, err := Compression{}.compressBody([]byte(strings.Repeat("a", 1000)))
+	require.NoError(t, err)
+	r := body.reader()
+	body.release()
+
+	// the buffer is kept until the last reader is closed
+	require.NotNil(t, body.buf)
+	assert.Equal(t, strings.Repeat("a", 1000), decompressBody(t, "gzip", r))
+	require.NoError(t, r.Close())
+	require.NoError(t, r.Close())
+	assert.Nil(t, body.buf)
+}
+
+func TestCompressionConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 20 {
+		compression := Compression{Codec: CompressionGzip}
+		if i%2 == 0 {
+			compression.Codec = CompressionZstd
+		}
+		data := bytes.Repeat([]byte{byte('a' + i)}, 1000+i)
+		wg.Go(func() {
+			body, err := compression.compressBody(data)
+			assert.NoError(t, err)
+			defer body.release()
+			b, err := io.ReadAll(body.reader())
+			assert.NoError(t, err)
+			var decompressed []byte
+			if compression.Codec == CompressionZstd {
+				dec, err := zstd.NewReader(nil)
+				assert.NoError(t, err)
+				defer dec.Close()
+				decompressed, err = dec.DecodeAll(b, nil)
+				assert.NoError(t, err)
+			} else {
+				gr, err := gzip.NewReader(bytes.NewReader(b))
+				assert.NoError(t, err)
+				decompressed, err = io.ReadAll(gr)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, data, decompressed)
+		})
+	}
+	wg.Wait()
+}
+
+func TestCompressionValidate(t *testing.T) {
+	assert.EqualError(t, Compression{Codec: "br"}.validate(), `unsupported compression codec: "br"`)
+	assert.EqualError(t, Compression{Level: compressionLevel(10)}.validate(), "invalid gzip compression level: 10")
+	assert.EqualError(t, Compression{Level: compressionLevel(-3)}.validate(), "invalid gzip compression level: -3")
+	assert.EqualError(t, Compression{Codec: CompressionZstd, Level: compressionLevel(-1)}.validate(), "invalid zstd compression level: -1")
+}
+
+func TestWriteWithCompression(t *testing.T) {
+	lp := strings.Repeat("cpu,host=h1 usage=0.5 1700000000000000000\n", 10)
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Content-Encoding")+":"+decompressBody(t, r.Header.Get("Content-Encoding"), r.Body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	err = c.Write(context.Background(), []byte(lp), WithGzipThreshold(1), WithCompression(CompressionZstd, 3))
+	require.NoError(t, err)
+	err = c.Write(context.Background(), []byte(lp), WithGzipThreshold(1), WithCompression(CompressionGzip, gzip.BestSpeed))
+	require.NoError(t, err)
+	err = c.Write(context.Background(), []byte(lp), WithGzipThreshold(0), WithCompression(CompressionZstd, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zstd:" + lp, "gzip:" + lp, ":" + lp}, received)
+
+	err = c.Write(context.Background(), []byte(lp), WithCompression("br", 0))
+	assert.EqualError(t, err, `invalid write options: unsupported compression codec: "br"`)
+}
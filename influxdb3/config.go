@@ -156,6 +156,36 @@ type ClientConfig struct {
 
 	// Flight client middleware
 	Middleware []flight.ClientMiddleware
+
+	// UseFlightSQL executes queries using the standard Arrow Flight SQL protocol instead of
+	// the InfluxDB specific ticket. Flight SQL supports SQL queries only.
+	// Prepared statements and catalog metadata calls use Flight SQL regardless of this setting.
+	// Default value: false.
+	UseFlightSQL bool
+
+	// UseFlightInfo executes queries by GetFlightInfo and reads all the endpoints of the returned
+	// FlightInfo in parallel, connecting to the locations of the endpoints. By default, the result is
+	// read by a single DoGet of the query ticket. It is ignored when UseFlightSQL is set.
+	// Default value: false.
+	UseFlightInfo bool
+
+	// FlightInfoForwardToken sends the Token also to endpoint locations on a host other than Host.
+	// By default, the authorization header is removed from the requests to such locations.
+	// Default value: false.
+	FlightInfoForwardToken bool
+
+	// Spool enables storing of failed writes on disk for later replay, see SpoolOptions.
+	// Default value: nil (disabled).
+	Spool *SpoolOptions
+
+	// WriteRateLimit throttles write requests on the client side, see RateLimit.
+	// Default value: nil (no limit).
+	WriteRateLimit *RateLimit
+
+	// QueryRetry is the retry policy of queries failing with transient gRPC errors, see QueryRetryOptions.
+	// It can be overridden per query by WithQueryRetry.
+	// Default value: nil (disabled).
+	QueryRetry *QueryRetryOptions
 }
 
 // validate validates the config.
@@ -166,6 +196,11 @@ func (c *ClientConfig) validate() error {
 	if c.Token == "" {
 		return errors.New("no token specified")
 	}
+	if c.WriteRateLimit != nil {
+		if err := c.WriteRateLimit.validate(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,377 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/float16"
+)
+
+// ExportFormat is the output format of Export.
+type ExportFormat int
+
+const (
+	// ExportCSV writes a header with column names followed by one CSV record per row.
+	ExportCSV ExportFormat = iota
+	// ExportJSON writes a JSON array of objects, one object per row.
+	ExportJSON
+	// ExportNDJSON writes one JSON object per line.
+	ExportNDJSON
+	// ExportLineProtocol writes one line protocol record per row. Tags and fields are
+	// distinguished by the iox::column::type metadata of the columns, columns without
+	// metadata are written as fields.
+	ExportLineProtocol
+)
+
+// String returns the name of the format.
+func (f ExportFormat) String() string {
+	switch f {
+	case ExportCSV:
+		return "CSV"
+	case ExportJSON:
+		return "JSON"
+	case ExportNDJSON:
+		return "NDJSON"
+	case ExportLineProtocol:
+		return "LineProtocol"
+	default:
+		return fmt.Sprintf("ExportFormat(%d)", int(f))
+	}
+}
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// Format of the output.
+	// Default value: ExportCSV.
+	Format ExportFormat
+
+	// TimeFormat is the layout of timestamps in CSV and JSON output, see time.Layout.
+	// Default value: time.RFC3339Nano.
+	TimeFormat string
+
+	// Measurement is the measurement of line protocol records for results without
+	// a measurement column.
+	Measurement string
+
+	// Precision of timestamps in line protocol output.
+	// Default value: Nanosecond.
+	Precision Precision
+}
+
+// Export streams query results read from reader to w in the format given by options.
+// Rows are written as they are read, the whole result is never kept in memory.
+//
+// The CSV header is made of the columns of the first record, an empty result with unknown schema
+// produces no CSV output.
+// NULL values are written as an empty CSV value, a JSON null, or are omitted in line protocol.
+// Binary values are written base64 encoded in CSV and JSON.
+//
+// Parameters:
+//   - w: The destination of the exported data.
+//   - reader: The query result, e.g. QueryIterator.Raw().
+//   - options: The export options.
+//
+// Returns:
+//   - An error, if any.
+func Export(w io.Writer, reader RecordReader, options ExportOptions) error {
+	if options.TimeFormat == "" {
+		options.TimeFormat = time.RFC3339Nano
+	}
+
+	var e exporter
+	bw := bufio.NewWriter(w)
+	switch options.Format {
+	case ExportCSV:
+		e = &csvExporter{w: csv.NewWriter(bw), options: &options}
+	case ExportJSON:
+		e = &jsonExporter{w: bw, options: &options, array: true}
+	case ExportNDJSON:
+		e = &jsonExporter{w: bw, options: &options}
+	case ExportLineProtocol:
+		e = &lpExporter{w: bw, options: &options}
+	default:
+		return fmt.Errorf("unsupported export format: %v", options.Format)
+	}
+
+	// The schema of the first record is used, readers of FlightInfo endpoints
+	// may not know the schema before the first record is read.
+	begun := false
+	for reader.Next() {
+		record := reader.RecordBatch()
+		if !begun {
+			if err := e.begin(record.Schema()); err != nil {
+				return err
+			}
+			begun = true
+		}
+		for row := range int(record.NumRows()) {
+			if err := e.row(record, row); err != nil {
+				return err
+			}
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return err
+	}
+	if !begun {
+		if err := e.begin(reader.Schema()); err != nil {
+			return err
+		}
+	}
+	if err := e.end(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// QueryExport queries data from InfluxDB v3 and streams the result to w, see Export.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - w: The destination of the exported data.
+//   - query: The query string to execute.
+//   - exportOptions: The export options.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - An error, if any.
+func (c *Client) QueryExport(ctx context.Context, w io.Writer, query string, exportOptions ExportOptions,
+	options ...QueryOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reader, err := c.getReader(ctx, query, nil, newQueryOptions(&DefaultQueryOptions, options))
+	if err != nil {
+		return err
+	}
+	defer releaseReader(reader)
+
+	return Export(w, reader, exportOptions)
+}
+
+// exporter writes rows in one of the export formats.
+type exporter interface {
+	begin(schema *arrow.Schema) error
+	row(record arrow.RecordBatch, row int) error
+	end() error
+}
+
+// exportValue returns the value of a column converted to a plain Go type,
+// timestamps are returned as time.Time.
+func exportValue(col arrow.Array, field arrow.Field, row int) (any, error) {
+	value, _, err := getArrowValue(col, field, row)
+	if err != nil {
+		return nil, fmt.Errorf("column '%s': %w", field.Name, err)
+	}
+	switch v := value.(type) {
+	case arrow.Timestamp:
+		unit := arrow.Nanosecond
+		if tt, ok := field.Type.(*arrow.TimestampType); ok {
+			unit = tt.Unit
+		}
+		return v.ToTime(unit), nil
+	case float16.Num:
+		return v.Float32(), nil
+	}
+	return value, nil
+}
+
+type csvExporter struct {
+	w       *csv.Writer
+	options *ExportOptions
+	values  []string
+}
+
+func (e *csvExporter) begin(schema *arrow.Schema) error {
+	if schema == nil {
+		// empty result with unknown schema
+		return nil
+	}
+	header := make([]string, schema.NumFields())
+	for i, f := range schema.Fields() {
+		header[i] = f.Name
+	}
+	e.values = make([]string, len(header))
+	return e.w.Write(header)
+}
+
+func (e *csvExporter) row(record arrow.RecordBatch, row int) error {
+	if int(record.NumCols()) != len(e.values) {
+		return fmt.Errorf("record with %d columns does not match CSV header with %d columns", record.NumCols(), len(e.values))
+	}
+	schema := record.Schema()
+	for ci, col := range record.Columns() {
+		value, err := exportValue(col, schema.Field(ci), row)
+		if err != nil {
+			return err
+		}
+		e.values[ci] = e.format(value)
+	}
+	return e.w.Write(e.values)
+}
+
+func (e *csvExporter) format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(e.options.TimeFormat)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (e *csvExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonExporter struct {
+	w       *bufio.Writer
+	options *ExportOptions
+	// array writes rows as a JSON array instead of newline delimited objects
+	array bool
+	rows  int
+}
+
+func (e *jsonExporter) begin(*arrow.Schema) error {
+	if e.array {
+		_, err := e.w.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+func (e *jsonExporter) row(record arrow.RecordBatch, row int) error {
+	if e.array && e.rows > 0 {
+		_ = e.w.WriteByte(',')
+	}
+	e.rows++
+
+	// columns are written in the order of the schema
+	schema := record.Schema()
+	_ = e.w.WriteByte('{')
+	for ci, col := range record.Columns() {
+		field := schema.Field(ci)
+		value, err := exportValue(col, field, row)
+		if err != nil {
+			return err
+		}
+		if ci > 0 {
+			_ = e.w.WriteByte(',')
+		}
+		if err := e.write(field.Name); err != nil {
+			return err
+		}
+		_ = e.w.WriteByte(':')
+		if err := e.write(e.jsonValue(value)); err != nil {
+			return fmt.Errorf("column '%s': %w", field.Name, err)
+		}
+	}
+	_ = e.w.WriteByte('}')
+	if !e.array {
+		return e.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (e *jsonExporter) jsonValue(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(e.options.TimeFormat)
+	case float64:
+		// JSON does not support NaN and infinity
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+	}
+	return value
+}
+
+func (e *jsonExporter) write(value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonExporter) end() error {
+	if e.array {
+		_, err := e.w.WriteString("]\n")
+		return err
+	}
+	return nil
+}
+
+type lpExporter struct {
+	w       *bufio.Writer
+	options *ExportOptions
+}
+
+func (e *lpExporter) begin(*arrow.Schema) error {
+	return nil
+}
+
+func (e *lpExporter) row(record arrow.RecordBatch, row int) error {
+	values, err := rowToPointValueWithError(record, row)
+	if err != nil {
+		return err
+	}
+	if values.GetMeasurement() == "" {
+		values.SetMeasurement(e.options.Measurement)
+	}
+	b, err := NewPointWithPointValues(values).MarshalBinary(e.options.Precision)
+	if err != nil {
+		return fmt.Errorf("row %d: %w", row, err)
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *lpExporter) end() error {
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTestReader(t *testing.T) RecordReader { //nolint:ireturn
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "iox::measurement", Type: arrow.BinaryTypes.String},
+		{Name: "host", Type: arrow.BinaryTypes.String, Nullable: true,
+			Metadata: arrow.NewMetadata([]string{"iox::column::type"}, []string{"iox::column_type::tag"})},
+		{Name: "usage", Type: arrow.PrimitiveTypes.Float64, Nullable: true,
+			Metadata: arrow.NewMetadata([]string{"iox::column::type"}, []string{"iox::column_type::field::float"})},
+		{Name: "count", Type: arrow.PrimitiveTypes.Int64, Nullable: true,
+			Metadata: arrow.NewMetadata([]string{"iox::column::type"}, []string{"iox::column_type::field::integer"})},
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond},
+			Metadata: arrow.NewMetadata([]string{"iox::column::type"}, []string{"iox::column_type::timestamp"})},
+	}, nil)
+
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+	rb.Field(0).(*array.StringBuilder).AppendValues([]string{"cpu", "cpu"}, nil)
+	rb.Field(1).(*array.StringBuilder).AppendValues([]string{"a,b", ""}, []bool{true, false})
+	rb.Field(2).(*array.Float64Builder).AppendValues([]float64{1.5, math.NaN()}, nil)
+	rb.Field(3).(*array.Int64Builder).AppendValues([]int64{0, 7}, []bool{false, true})
+	rb.Field(4).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{1_000_000_000, 2_000_000_000}, nil)
+	rec0 := rb.NewRecord()
+	t.Cleanup(rec0.Release)
+	rec1 := rb.NewRecord() // empty record
+	t.Cleanup(rec1.Release)
+
+	return &recordsReader{records: []arrow.RecordBatch{rec0, rec1}}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := Export(&buf, exportTestReader(t), ExportOptions{TimeFormat: "2006-01-02T15:04:05Z07:00"})
+	require.NoError(t, err)
+	assert.Equal(t, "iox::measurement,host,usage,count,time\n"+
+		"cpu,\"a,b\",1.5,,1970-01-01T00:00:01Z\n"+
+		"cpu,,NaN,7,1970-01-01T00:00:02Z\n", buf.String())
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := Export(&buf, exportTestReader(t), ExportOptions{Format: ExportJSON})
+	require.NoError(t, err)
+	assert.Equal(t, `[{"iox::measurement":"cpu","host":"a,b","usage":1.5,"count":null,"time":"1970-01-01T00:00:01Z"},`+
+		`{"iox::measurement":"cpu","host":null,"usage":null,"count":7,"time":"1970-01-01T00:00:02Z"}]`+"\n", buf.String())
+
+	// empty result
+	reader := exportTestReader(t).(*recordsReader)
+	reader.records = reader.records[1:]
+	buf.Reset()
+	err = Export(&buf, reader, ExportOptions{Format: ExportJSON})
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestExportNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := Export(&buf, exportTestReader(t), ExportOptions{Format: ExportNDJSON, TimeFormat: "15:04:05"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"iox::measurement":"cpu","host":"a,b","usage":1.5,"count":null,"time":"00:00:01"}`+"\n"+
+		`{"iox::measurement":"cpu","host":null,"usage":null,"count":7,"time":"00:00:02"}`+"\n", buf.String())
+}
+
+func TestExportLineProtocol(t *testing.T) {
+	var buf bytes.Buffer
+	err := Export(&buf, exportTestReader(t), ExportOptions{Format: ExportLineProtocol, Precision: Second})
+	require.NoError(t, err)
+	// NaN field is omitted, the row without fields is skipped
+	assert.Equal(t, "cpu,host=a\\,b usage=1.5 1\ncpu count=7i 2\n", buf.String())
+}
+
+func TestExportInvalidFormat(t *testing.T) {
+	err := Export(&bytes.Buffer{}, exportTestReader(t), ExportOptions{Format: ExportFormat(100)})
+	assert.EqualError(t, err, "unsupported export format: ExportFormat(100)")
+}
+
+func TestQueryExportSchemaLessFlightInfo(t *testing.T) {
+	server := &endpointFlightServer{
+		name: "main",
+		endpoints: []*flight.FlightEndpoint{
+			{Ticket: &flight.Ticket{Ticket: []byte("t0")}},
+			{Ticket: &flight.Ticket{Ticket: []byte("t1")}},
+		},
+	}
+	client := startFlightServer(t, server, useFlightInfo)
+
+	var buf bytes.Buffer
+	err := client.QueryExport(context.Background(), &buf, "SELECT source FROM t", ExportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "source\nmain:t0\nmain:t1\n", buf.String())
+
+	// empty result
+	client = startFlightServer(t, &endpointFlightServer{name: "main"}, useFlightInfo)
+	buf.Reset()
+	err = client.QueryExport(context.Background(), &buf, "SELECT source FROM t", ExportOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,297 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// endpointBufferedRecords is the number of records buffered for each endpoint read in parallel.
+const endpointBufferedRecords = 4
+
+// reuseConnectionLocation is the location of an endpoint served by the connection of the FlightInfo request.
+const reuseConnectionLocation = "arrow-flight-reuse-connection://?"
+
+// getFlightInfoReader executes a query ticket by GetFlightInfo and reads the endpoints of the result in parallel.
+func (c *Client) getFlightInfoReader(ctx context.Context, ticket []byte, opts []grpc.CallOption) (RecordReader, error) { //nolint:ireturn
+	// the context is canceled when the result is read or the reader is released
+	ctx, cancel := c.queryTimeoutContext(ctx)
+
+	descriptor := &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: ticket}
+	info, err := c.queryClient.GetFlightInfo(ctx, descriptor, opts...)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("flight get flight info: %w", err)
+	}
+	reader, err := c.newEndpointsReader(ctx, cancel, info, opts)
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// newEndpointsReader reads the endpoints of info in parallel. cancel cancels ctx,
+// it is called when the result is read, the reader is released or an error is returned.
+func (c *Client) newEndpointsReader(ctx context.Context, cancel context.CancelFunc, info *flight.FlightInfo,
+	opts []grpc.CallOption) (*endpointsReader, error) {
+	r := &endpointsReader{
+		cancel:  cancel,
+		results: make([]chan endpointResult, len(info.GetEndpoint())),
+	}
+	if len(info.GetSchema()) > 0 {
+		var err error
+		if r.schema, err = flight.DeserializeSchema(info.GetSchema(), c.allocator); err != nil {
+			cancel()
+			return nil, fmt.Errorf("flight info schema: %w", err)
+		}
+	}
+
+	// the response headers and trailers are those of the request returning the FlightInfo,
+	// the endpoints are read concurrently
+	doGetOpts := make([]grpc.CallOption, 0, len(opts))
+	for _, opt := range opts {
+		switch opt.(type) {
+		case grpc.HeaderCallOption, grpc.TrailerCallOption:
+		default:
+			doGetOpts = append(doGetOpts, opt)
+		}
+	}
+	for i, endpoint := range info.GetEndpoint() {
+		r.results[i] = make(chan endpointResult, endpointBufferedRecords)
+		go c.readEndpoint(ctx, endpoint, doGetOpts, r.results[i])
+	}
+	return r, nil
+}
+
+// readEndpoint sends the records of a single endpoint to results and closes it.
+func (c *Client) readEndpoint(ctx context.Context, endpoint *flight.FlightEndpoint, opts []grpc.CallOption,
+	results chan<- endpointResult) {
+	defer close(results)
+	send := func(result endpointResult) bool {
+		select {
+		case results <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	client, sendToken, err := c.endpointClients.get(c, endpoint.GetLocation())
+	if err != nil {
+		send(endpointResult{err: err})
+		return
+	}
+	callCtx := ctx
+	if !sendToken {
+		callCtx = withoutAuthorization(ctx)
+	}
+	stream, err := client.DoGet(callCtx, endpoint.GetTicket(), opts...)
+	if err != nil {
+		send(endpointResult{err: fmt.Errorf("flight do get: %w", err)})
+		return
+	}
+	reader, err := flight.NewRecordReader(stream, ipc.WithAllocator(c.allocator))
+	if err != nil {
+		send(endpointResult{err: fmt.Errorf("flight reader: %w", err)})
+		return
+	}
+	defer reader.Release()
+
+	for reader.Next() {
+		record := reader.RecordBatch()
+		record.Retain()
+		if !send(endpointResult{record: record}) {
+			record.Release()
+			return
+		}
+	}
+	if err := reader.Err(); err != nil {
+		send(endpointResult{err: err})
+	}
+}
+
+// endpointResult is a record or the error of an endpoint.
+type endpointResult struct {
+	record arrow.RecordBatch
+	err    error
+}
+
+// endpointsReader is a RecordReader returning the records of the endpoints of a FlightInfo
+// in the order of the endpoints, while the endpoints are read in parallel.
+type endpointsReader struct {
+	cancel  context.CancelFunc
+	results []chan endpointResult
+	// index of the endpoint being read
+	current int
+	record  arrow.RecordBatch
+	schema  *arrow.Schema
+	err     error
+}
+
+// Next moves to the next record and returns true if a record is present.
+func (r *endpointsReader) Next() bool {
+	r.releaseRecord()
+	for r.err == nil && r.current < len(r.results) {
+		result, ok := <-r.results[r.current]
+		if !ok {
+			r.current++
+			continue
+		}
+		if result.err != nil {
+			r.err = fmt.Errorf("flight endpoint %d: %w", r.current, result.err)
+			break
+		}
+		r.record = result.record
+		if r.schema == nil {
+			r.schema = r.record.Schema()
+		}
+		return true
+	}
+	r.Release()
+	return false
+}
+
+// RecordBatch returns the current record.
+func (r *endpointsReader) RecordBatch() arrow.RecordBatch { //nolint:ireturn
+	return r.record
+}
+
+// Err returns the first error of reading the endpoints.
+func (r *endpointsReader) Err() error {
+	return r.err
+}
+
+// Schema returns the schema of the FlightInfo, or of the first record if the FlightInfo has no schema.
+func (r *endpointsReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+// Release cancels reading of the endpoints and releases the received records.
+// It is called automatically when the last record is read or an error occurs.
+func (r *endpointsReader) Release() {
+	r.releaseRecord()
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.cancel = nil
+	pending := r.results[min(r.current, len(r.results)):]
+	r.current = len(r.results)
+	if len(pending) > 0 {
+		// the canceled endpoints complete in the background
+		go func() {
+			for _, ch := range pending {
+				for result := range ch {
+					if result.record != nil {
+						result.record.Release()
+					}
+				}
+			}
+		}()
+	}
+}
+
+func (r *endpointsReader) releaseRecord() {
+	if r.record != nil {
+		r.record.Release()
+		r.record = nil
+	}
+}
+
+// endpointClients caches Flight clients of endpoint locations.
+type endpointClients struct {
+	mu      sync.Mutex
+	clients map[string]flight.Client
+}
+
+// get returns the client of the first location of an endpoint, the query client when the endpoint
+// has no location. sendToken reports whether the token may be sent to the location, which is true
+// for the host of the client configuration or when ClientConfig.FlightInfoForwardToken is set.
+func (e *endpointClients) get(c *Client, locations []*flight.Location) (client flight.Client, sendToken bool, err error) { //nolint:ireturn
+	if len(locations) == 0 || locations[0].GetUri() == reuseConnectionLocation {
+		return c.queryClient, true, nil
+	}
+	uri := locations[0].GetUri()
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, false, fmt.Errorf("flight endpoint location: %w", err)
+	}
+	var secure bool
+	switch u.Scheme {
+	case "grpc", "grpc+tcp":
+		if c.apiURL.Scheme == schemeHTTPS {
+			return nil, false, fmt.Errorf("insecure flight endpoint location refused for a TLS connection: %s", uri)
+		}
+	case "grpc+tls":
+		secure = true
+	default:
+		return nil, false, fmt.Errorf("unsupported flight endpoint location: %s", uri)
+	}
+	sendToken = c.config.FlightInfoForwardToken || strings.EqualFold(u.Hostname(), c.apiURL.Hostname())
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if client, ok := e.clients[uri]; ok {
+		return client, sendToken, nil
+	}
+	client, err = flight.NewClientWithMiddleware(u.Host, nil, c.config.Middleware, c.queryTransport(secure))
+	if err != nil {
+		return nil, false, fmt.Errorf("flight endpoint location %s: %w", uri, err)
+	}
+	if e.clients == nil {
+		e.clients = make(map[string]flight.Client)
+	}
+	e.clients[uri] = client
+	return client, sendToken, nil
+}
+
+// withoutAuthorization returns ctx without the authorization header of the query request.
+func withoutAuthorization(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Delete("authorization")
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// close closes the cached clients.
+func (e *endpointClients) close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var errs []error
+	for _, client := range e.clients {
+		errs = append(errs, client.Close())
+	}
+	e.clients = nil
+	return errors.Join(errs...)
+}
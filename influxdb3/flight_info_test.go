@@ -0,0 +1,201 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3/testutil"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// endpointFlightServer returns the endpoints given by locations from GetFlightInfo,
+// DoGet returns a row with the name of the server and the ticket.
+type endpointFlightServer struct {
+	flight.BaseFlightServer
+	name      string
+	endpoints []*flight.FlightEndpoint
+	mu        sync.Mutex
+	query     string
+	tokens    []string
+}
+
+func (f *endpointFlightServer) GetFlightInfo(_ context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	var ticket map[string]any
+	if err := json.Unmarshal(desc.GetCmd(), &ticket); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.query, _ = ticket["sql_query"].(string)
+	f.mu.Unlock()
+	return &flight.FlightInfo{FlightDescriptor: desc, Endpoint: f.endpoints}, nil
+}
+
+func (f *endpointFlightServer) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
+	md, _ := metadata.FromIncomingContext(fs.Context())
+	f.mu.Lock()
+	f.tokens = append(f.tokens, md.Get("authorization")...)
+	f.mu.Unlock()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "source", Type: arrow.BinaryTypes.String},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).Append(f.name + ":" + string(tkt.GetTicket()))
+	rec := builder.NewRecord()
+	defer rec.Release()
+
+	w := flight.NewRecordWriter(fs, ipc.WithSchema(schema))
+	return w.Write(rec)
+}
+
+func useFlightInfo(config *ClientConfig) {
+	config.UseFlightInfo = true
+}
+
+func TestQueryFlightInfoEndpoints(t *testing.T) {
+	remote := testutil.StartFlightServer(t, &endpointFlightServer{name: "remote"})
+	location := []*flight.Location{{Uri: "grpc+tcp://" + remote}}
+	server := &endpointFlightServer{
+		name: "main",
+		endpoints: []*flight.FlightEndpoint{
+			{Ticket: &flight.Ticket{Ticket: []byte("t0")}},
+			{Ticket: &flight.Ticket{Ticket: []byte("t1")}, Location: location},
+			{Ticket: &flight.Ticket{Ticket: []byte("t2")}, Location: []*flight.Location{{Uri: reuseConnectionLocation}}},
+			{Ticket: &flight.Ticket{Ticket: []byte("t3")}, Location: location},
+		},
+	}
+	client := startFlightServer(t, server, useFlightInfo)
+
+	it, err := client.Query(context.Background(), "SELECT source FROM t")
+	require.NoError(t, err)
+	defer it.Close()
+
+	var sources []any
+	for it.Next() {
+		sources = append(sources, it.Value()["source"])
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []any{"main:t0", "remote:t1", "main:t2", "remote:t3"}, sources)
+	server.mu.Lock()
+	assert.Equal(t, "SELECT source FROM t", server.query)
+	server.mu.Unlock()
+
+	// the client of the remote location is reused
+	client.endpointClients.mu.Lock()
+	assert.Len(t, client.endpointClients.clients, 1)
+	client.endpointClients.mu.Unlock()
+}
+
+func TestQueryFlightInfoUnsupportedLocation(t *testing.T) {
+	server := &endpointFlightServer{
+		name: "main",
+		endpoints: []*flight.FlightEndpoint{
+			{Ticket: &flight.Ticket{Ticket: []byte("t0")}},
+			{Ticket: &flight.Ticket{Ticket: []byte("t1")}, Location: []*flight.Location{{Uri: "http://localhost:1"}}},
+		},
+	}
+	client := startFlightServer(t, server, useFlightInfo)
+
+	it, err := client.Query(context.Background(), "SELECT source FROM t")
+	require.NoError(t, err)
+	defer it.Close()
+
+	require.True(t, it.Next())
+	assert.Equal(t, "main:t0", it.Value()["source"])
+	assert.False(t, it.Next())
+	require.ErrorContains(t, it.Err(), "flight endpoint 1: unsupported flight endpoint location: http://localhost:1")
+}
+
+func TestQueryFlightInfoInsecureLocationRefused(t *testing.T) {
+	client, err := New(ClientConfig{
+		Host:          "https://localhost:8443",
+		Token:         "my_secret_token",
+		Database:      "explore",
+		UseFlightInfo: true,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	for _, uri := range []string{"grpc://localhost:8443", "grpc+tcp://localhost:8443"} {
+		_, _, err = client.endpointClients.get(client, []*flight.Location{{Uri: uri}})
+		require.EqualError(t, err, "insecure flight endpoint location refused for a TLS connection: "+uri)
+	}
+	_, sendToken, err := client.endpointClients.get(client, []*flight.Location{{Uri: "grpc+tls://localhost:8443"}})
+	require.NoError(t, err)
+	assert.True(t, sendToken)
+}
+
+func TestQueryFlightInfoTokenForwarding(t *testing.T) {
+	for _, forward := range []bool{false, true} {
+		remote := &endpointFlightServer{name: "remote"}
+		_, port, err := net.SplitHostPort(testutil.StartFlightServer(t, remote))
+		require.NoError(t, err)
+		// the server listens on 127.0.0.1, localhost is another host than that of the client
+		server := &endpointFlightServer{
+			name: "main",
+			endpoints: []*flight.FlightEndpoint{
+				{Ticket: &flight.Ticket{Ticket: []byte("t0")}, Location: []*flight.Location{{Uri: "grpc+tcp://localhost:" + port}}},
+			},
+		}
+		client := startFlightServer(t, server, func(config *ClientConfig) {
+			config.UseFlightInfo = true
+			config.FlightInfoForwardToken = forward
+		})
+
+		it, err := client.Query(context.Background(), "SELECT source FROM t")
+		require.NoError(t, err)
+		require.True(t, it.Next())
+		assert.Equal(t, "remote:t0", it.Value()["source"])
+		require.NoError(t, it.Close())
+
+		remote.mu.Lock()
+		if forward {
+			assert.Equal(t, []string{"Bearer my_secret_token"}, remote.tokens)
+		} else {
+			assert.Empty(t, remote.tokens)
+		}
+		remote.mu.Unlock()
+	}
+}
+
+func TestQueryFlightInfoNoEndpoints(t *testing.T) {
+	client := startFlightServer(t, &endpointFlightServer{name: "main"}, useFlightInfo)
+
+	it, err := client.Query(context.Background(), "SELECT source FROM t")
+	require.NoError(t, err)
+	assert.False(t, it.Next())
+	require.NoError(t, it.Err())
+}
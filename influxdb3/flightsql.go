@@ -0,0 +1,450 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// PreparedStatement is a Flight SQL prepared statement. It can be executed
+// many times with different parameters and must be closed when no longer used.
+// It is safe for concurrent use, executions are serialized.
+type PreparedStatement struct {
+	client  *Client
+	options *QueryOptions
+	mu      sync.Mutex
+	stmt    *flightsql.PreparedStatement
+}
+
+// GetTablesOptions filters the tables returned by Client.GetTables.
+// Patterns use the SQL LIKE syntax, empty values do not filter.
+type GetTablesOptions struct {
+	// Catalog of the tables.
+	Catalog string
+	// DBSchemaFilterPattern filters database schemas.
+	DBSchemaFilterPattern string
+	// TableNameFilterPattern filters table names.
+	TableNameFilterPattern string
+	// TableTypes filters table types, e.g. "BASE TABLE" or "VIEW".
+	TableTypes []string
+	// IncludeSchema adds the Arrow schema of each table to the result.
+	IncludeSchema bool
+}
+
+// GetDBSchemasOptions filters the database schemas returned by Client.GetDBSchemas.
+// Patterns use the SQL LIKE syntax, empty values do not filter.
+type GetDBSchemasOptions struct {
+	// Catalog of the database schemas.
+	Catalog string
+	// DBSchemaFilterPattern filters database schemas.
+	DBSchemaFilterPattern string
+}
+
+// Prepare creates a Flight SQL prepared statement of a SQL query.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - query: The query string with $parameter placeholders.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - The prepared statement (*PreparedStatement).
+//   - An error, if any.
+func (c *Client) Prepare(ctx context.Context, query string, options ...QueryOption) (*PreparedStatement, error) {
+	opts := newQueryOptions(&DefaultQueryOptions, options)
+	ctx, err := c.flightSQLContext(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := c.flightSQLClient().Prepare(ctx, query, opts.GrpcCallOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("flight sql prepare: %w", err)
+	}
+	return &PreparedStatement{client: c, options: opts, stmt: stmt}, nil
+}
+
+// Query executes the prepared statement with the given parameters.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - parameters: The query parameters, nil if the statement has no parameters.
+//
+// Returns:
+//   - A result iterator (*QueryIterator).
+//   - An error, if any.
+func (s *PreparedStatement) Query(ctx context.Context, parameters QueryParameters) (*QueryIterator, error) {
+	reader, err := s.client.openReader(ctx, s.options, func(opts *QueryOptions) (RecordReader, error) {
+		ctx, err := s.client.flightSQLContext(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := s.client.queryTimeoutContext(ctx)
+
+		info, err := s.execute(ctx, parameters, opts.GrpcCallOptions)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		reader, err := s.client.newEndpointsReader(ctx, cancel, info, opts.GrpcCallOptions)
+		if err != nil {
+			return nil, err
+		}
+		return reader, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryIteratorFromReader(reader), nil
+}
+
+func (s *PreparedStatement) execute(ctx context.Context, parameters QueryParameters, opts []grpc.CallOption) (*flight.FlightInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// always bind, empty parameters must not reuse the binding of a previous execution
+	var record arrow.RecordBatch
+	if schema := s.stmt.ParameterSchema(); len(parameters) > 0 || (schema != nil && schema.NumFields() > 0) {
+		var err error
+		record, err = parametersRecord(schema, parameters)
+		if err != nil {
+			return nil, err
+		}
+		defer record.Release()
+	}
+	s.stmt.SetParameters(record)
+	info, err := s.stmt.Execute(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("flight sql execute: %w", err)
+	}
+	return info, nil
+}
+
+// ParameterSchema returns the schema of the statement parameters, if provided by the server.
+func (s *PreparedStatement) ParameterSchema() *arrow.Schema {
+	return s.stmt.ParameterSchema()
+}
+
+// DatasetSchema returns the schema of the statement results, if provided by the server.
+func (s *PreparedStatement) DatasetSchema() *arrow.Schema {
+	return s.stmt.DatasetSchema()
+}
+
+// Close closes the prepared statement on the server.
+func (s *PreparedStatement) Close(ctx context.Context) error {
+	ctx, err := s.client.flightSQLContext(ctx, s.options)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stmt.Close(ctx, s.options.GrpcCallOptions...)
+}
+
+// GetCatalogs returns the catalogs available on the server using Flight SQL.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A result iterator (*QueryIterator) with the catalog_name column.
+//   - An error, if any.
+func (c *Client) GetCatalogs(ctx context.Context, options ...QueryOption) (*QueryIterator, error) {
+	return c.flightSQLCatalogQuery(ctx, options,
+		func(ctx context.Context, client *flightsql.Client, opts []grpc.CallOption) (*flight.FlightInfo, error) {
+			return client.GetCatalogs(ctx, opts...)
+		})
+}
+
+// GetDBSchemas returns the database schemas available on the server using Flight SQL.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - filter: The filter of returned database schemas.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A result iterator (*QueryIterator) with the catalog_name and db_schema_name columns.
+//   - An error, if any.
+func (c *Client) GetDBSchemas(ctx context.Context, filter GetDBSchemasOptions, options ...QueryOption) (*QueryIterator, error) {
+	return c.flightSQLCatalogQuery(ctx, options,
+		func(ctx context.Context, client *flightsql.Client, opts []grpc.CallOption) (*flight.FlightInfo, error) {
+			return client.GetDBSchemas(ctx, &flightsql.GetDBSchemasOpts{
+				Catalog:               optionalString(filter.Catalog),
+				DbSchemaFilterPattern: optionalString(filter.DBSchemaFilterPattern),
+			}, opts...)
+		})
+}
+
+// GetTables returns the tables available on the server using Flight SQL.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - filter: The filter of returned tables.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A result iterator (*QueryIterator) with the catalog_name, db_schema_name, table_name,
+//     table_type and optionally table_schema columns.
+//   - An error, if any.
+func (c *Client) GetTables(ctx context.Context, filter GetTablesOptions, options ...QueryOption) (*QueryIterator, error) {
+	return c.flightSQLCatalogQuery(ctx, options,
+		func(ctx context.Context, client *flightsql.Client, opts []grpc.CallOption) (*flight.FlightInfo, error) {
+			return client.GetTables(ctx, &flightsql.GetTablesOpts{
+				Catalog:                optionalString(filter.Catalog),
+				DbSchemaFilterPattern:  optionalString(filter.DBSchemaFilterPattern),
+				TableNameFilterPattern: optionalString(filter.TableNameFilterPattern),
+				TableTypes:             filter.TableTypes,
+				IncludeSchema:          filter.IncludeSchema,
+			}, opts...)
+		})
+}
+
+// GetSQLInfo returns information about the server and its SQL support using Flight SQL.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - info: The requested information, all available information when empty.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A result iterator (*QueryIterator) with the info_name and value columns.
+//   - An error, if any.
+func (c *Client) GetSQLInfo(ctx context.Context, info []flightsql.SqlInfo, options ...QueryOption) (*QueryIterator, error) {
+	return c.flightSQLCatalogQuery(ctx, options,
+		func(ctx context.Context, client *flightsql.Client, opts []grpc.CallOption) (*flight.FlightInfo, error) {
+			return client.GetSqlInfo(ctx, info, opts...)
+		})
+}
+
+func (c *Client) flightSQLCatalogQuery(ctx context.Context, options []QueryOption,
+	call func(context.Context, *flightsql.Client, []grpc.CallOption) (*flight.FlightInfo, error)) (*QueryIterator, error) {
+	reader, err := c.openReader(ctx, newQueryOptions(&DefaultQueryOptions, options), func(opts *QueryOptions) (RecordReader, error) {
+		ctx, err := c.flightSQLContext(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := c.queryTimeoutContext(ctx)
+
+		info, err := call(ctx, c.flightSQLClient(), opts.GrpcCallOptions)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("flight sql: %w", err)
+		}
+		reader, err := c.newEndpointsReader(ctx, cancel, info, opts.GrpcCallOptions)
+		if err != nil {
+			return nil, err
+		}
+		return reader, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryIteratorFromReader(reader), nil
+}
+
+// getFlightSQLReader executes the query using Flight SQL, a query with parameters
+// is executed as a prepared statement. ctx must already contain the request metadata.
+func (c *Client) getFlightSQLReader(ctx context.Context, database string, query string, parameters QueryParameters,
+	options *QueryOptions) (RecordReader, error) { //nolint:ireturn
+	if options.QueryType != SQL {
+		return nil, fmt.Errorf("%s queries are not supported by Flight SQL", options.QueryType)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, "database", database)
+	ctx, cancel := c.queryTimeoutContext(ctx)
+
+	client := c.flightSQLClient()
+	var info *flight.FlightInfo
+	var err error
+	if len(parameters) > 0 {
+		var stmt *flightsql.PreparedStatement
+		stmt, err = client.Prepare(ctx, query, options.GrpcCallOptions...)
+		if err == nil {
+			// the statement is closed when the reader is released, servers may
+			// drop the endpoints of a closed statement
+			cancelQuery := cancel
+			cancel = func() {
+				_ = stmt.Close(ctx, options.GrpcCallOptions...)
+				cancelQuery()
+			}
+			info, err = (&PreparedStatement{client: c, options: options, stmt: stmt}).execute(ctx, parameters, options.GrpcCallOptions)
+		}
+	} else {
+		info, err = client.Execute(ctx, query, options.GrpcCallOptions...)
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("flight sql: %w", err)
+	}
+	reader, err := c.newEndpointsReader(ctx, cancel, info, options.GrpcCallOptions)
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// flightSQLContext returns ctx with the gRPC metadata of a Flight SQL request.
+func (c *Client) flightSQLContext(ctx context.Context, options *QueryOptions) (context.Context, error) {
+	database, err := c.queryDatabase(options)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(c.queryContext(ctx, options), "database", database), nil
+}
+
+// queryTimeoutContext applies the configured QueryTimeout to ctx.
+func (c *Client) queryTimeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.config.QueryTimeout > 0 {
+		return context.WithTimeout(ctx, c.config.QueryTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// flightSQLClient returns a Flight SQL client sharing the connection of the query client.
+func (c *Client) flightSQLClient() *flightsql.Client {
+	return &flightsql.Client{Client: c.queryClient, Alloc: c.allocator}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// parametersRecord returns a single row record binding the parameters of a prepared statement.
+// The columns follow the parameter schema of the statement when it is known, otherwise the
+// parameter names are sorted.
+func parametersRecord(schema *arrow.Schema, parameters QueryParameters) (arrow.RecordBatch, error) { //nolint:ireturn
+	var names []string
+	if schema != nil && schema.NumFields() > 0 {
+		for _, f := range schema.Fields() {
+			names = append(names, f.Name)
+		}
+	} else {
+		for name := range parameters {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+	}
+
+	values := make([]any, len(names))
+	fields := make([]arrow.Field, len(names))
+	for i, name := range names {
+		value, ok := parameters[name]
+		if !ok {
+			// placeholders may be reported with the $ prefix
+			value, ok = parameters[strings.TrimPrefix(name, "$")]
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing value of query parameter '%s'", name)
+		}
+		typ, err := parameterType(value)
+		if err != nil {
+			return nil, fmt.Errorf("query parameter '%s': %w", name, err)
+		}
+		values[i] = value
+		fields[i] = arrow.Field{Name: name, Type: typ, Nullable: true}
+	}
+
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, arrow.NewSchema(fields, nil))
+	defer rb.Release()
+	for i, value := range values {
+		switch b := rb.Field(i).(type) {
+		case *array.StringBuilder:
+			b.Append(value.(string))
+		case *array.BooleanBuilder:
+			b.Append(value.(bool))
+		case *array.Int64Builder:
+			b.Append(toInt64(value))
+		case *array.Uint64Builder:
+			b.Append(toUint64(value))
+		case *array.Float64Builder:
+			b.Append(toFloat64(value))
+		default:
+			return nil, errors.New("unexpected parameter builder")
+		}
+	}
+	return rb.NewRecord(), nil
+}
+
+func parameterType(value any) (arrow.DataType, error) { //nolint:ireturn
+	switch value.(type) {
+	case string:
+		return arrow.BinaryTypes.String, nil
+	case bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case int, int8, int16, int32, int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case uint, uint8, uint16, uint32, uint64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case float32, float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+func toInt64(value any) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	default:
+		return value.(int64)
+	}
+}
+
+func toUint64(value any) uint64 {
+	switch v := value.(type) {
+	case uint:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	default:
+		return value.(uint64)
+	}
+}
+
+func toFloat64(value any) float64 {
+	if v, ok := value.(float32); ok {
+		return float64(v)
+	}
+	return value.(float64)
+}
@@ -0,0 +1,293 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fake Flight SQL server implementation returning the query text in the "query" column,
+// catalog calls fail catalogFailures times with the Unavailable code, prepared statements
+// count the bound parameter rows and record the closed statements
+type flightSQLServer struct {
+	flightsql.BaseServer
+	mu              sync.Mutex
+	databases       []string
+	catalogFailures int
+	bindings        int
+	closed          []string
+}
+
+func (s *flightSQLServer) GetFlightInfoStatement(ctx context.Context, cmd flightsql.StatementQuery,
+	desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		s.mu.Lock()
+		s.databases = append(s.databases, md.Get("database")...)
+		s.mu.Unlock()
+	}
+	ticket, err := flightsql.CreateStatementQueryTicket([]byte(cmd.GetQuery()))
+	if err != nil {
+		return nil, err
+	}
+	return &flight.FlightInfo{
+		FlightDescriptor: desc,
+		Endpoint: []*flight.FlightEndpoint{
+			{Ticket: &flight.Ticket{Ticket: ticket}},
+			{Ticket: &flight.Ticket{Ticket: ticket}},
+		},
+		TotalRecords: -1,
+		TotalBytes:   -1,
+	}, nil
+}
+
+func (s *flightSQLServer) DoGetStatement(_ context.Context,
+	cmd flightsql.StatementQueryTicket) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "query", Type: arrow.BinaryTypes.String}}, nil)
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+	rb.Field(0).(*array.StringBuilder).Append(string(cmd.GetStatementHandle()))
+
+	ch := make(chan flight.StreamChunk, 1)
+	ch <- flight.StreamChunk{Data: rb.NewRecord()}
+	close(ch)
+	return schema, ch, nil
+}
+
+func (s *flightSQLServer) GetFlightInfoCatalogs(_ context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.catalogFailures > 0 {
+		s.catalogFailures--
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	return &flight.FlightInfo{
+		FlightDescriptor: desc,
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: desc.GetCmd()}}},
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}, nil
+}
+
+func (s *flightSQLServer) DoGetCatalogs(context.Context) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "catalog_name", Type: arrow.BinaryTypes.String}}, nil)
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+	rb.Field(0).(*array.StringBuilder).Append("public")
+
+	ch := make(chan flight.StreamChunk, 1)
+	ch <- flight.StreamChunk{Data: rb.NewRecord()}
+	close(ch)
+	return schema, ch, nil
+}
+
+func (s *flightSQLServer) CreatePreparedStatement(_ context.Context,
+	req flightsql.ActionCreatePreparedStatementRequest) (flightsql.ActionCreatePreparedStatementResult, error) {
+	return flightsql.ActionCreatePreparedStatementResult{Handle: []byte(req.GetQuery())}, nil
+}
+
+func (s *flightSQLServer) DoPutPreparedStatementQuery(_ context.Context, cmd flightsql.PreparedStatementQuery,
+	r flight.MessageReader, _ flight.MetadataWriter) ([]byte, error) {
+	var rows int64
+	for r.Next() {
+		rows += r.RecordBatch().NumRows()
+	}
+	s.mu.Lock()
+	s.bindings += int(rows)
+	s.mu.Unlock()
+	return cmd.GetPreparedStatementHandle(), nil
+}
+
+func (s *flightSQLServer) GetFlightInfoPreparedStatement(_ context.Context, _ flightsql.PreparedStatementQuery,
+	desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return &flight.FlightInfo{
+		FlightDescriptor: desc,
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: desc.GetCmd()}}},
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}, nil
+}
+
+func (s *flightSQLServer) DoGetPreparedStatement(ctx context.Context,
+	cmd flightsql.PreparedStatementQuery) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	return s.DoGetStatement(ctx, preparedStatementTicket{cmd})
+}
+
+func (s *flightSQLServer) ClosePreparedStatement(_ context.Context, req flightsql.ActionClosePreparedStatementRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = append(s.closed, string(req.GetPreparedStatementHandle()))
+	return nil
+}
+
+// preparedStatementTicket returns the results of a prepared statement as those of its query
+type preparedStatementTicket struct {
+	flightsql.PreparedStatementQuery
+}
+
+func (t preparedStatementTicket) GetStatementHandle() []byte {
+	return t.GetPreparedStatementHandle()
+}
+
+func (s *flightSQLServer) closedStatements() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.closed)
+}
+
+func useFlightSQL(config *ClientConfig) {
+	config.UseFlightSQL = true
+}
+
+func TestQueryFlightSQL(t *testing.T) {
+	f := &flightSQLServer{}
+	client := startFlightServer(t, flightsql.NewFlightServer(f), useFlightSQL)
+
+	it, err := client.Query(context.Background(), "SELECT 1", WithDatabase("other"))
+	require.NoError(t, err)
+	var rows []any
+	for it.Next() {
+		rows = append(rows, it.Value()["query"])
+	}
+	require.NoError(t, it.Err())
+	// one row from each endpoint
+	assert.Equal(t, []any{"SELECT 1", "SELECT 1"}, rows)
+	assert.Equal(t, []string{"other"}, f.databases)
+}
+
+func TestQueryFlightSQLWithParameters(t *testing.T) {
+	f := &flightSQLServer{}
+	client := startFlightServer(t, flightsql.NewFlightServer(f), useFlightSQL)
+
+	it, err := client.QueryWithParameters(context.Background(), "SELECT $host", QueryParameters{"host": "a"})
+	require.NoError(t, err)
+	// the endpoints of the statement are read after the query returns
+	assert.Empty(t, f.closedStatements())
+	require.True(t, it.Next())
+	assert.Equal(t, "SELECT $host", it.Value()["query"])
+	assert.False(t, it.Next())
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"SELECT $host"}, f.closedStatements())
+}
+
+func TestPreparedStatementQueryClearsParameters(t *testing.T) {
+	f := &flightSQLServer{}
+	client := startFlightServer(t, flightsql.NewFlightServer(f), useFlightSQL)
+
+	stmt, err := client.Prepare(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	var rows []any
+	for _, parameters := range []QueryParameters{{"host": "a"}, nil} {
+		it, err := stmt.Query(context.Background(), parameters)
+		require.NoError(t, err)
+		for it.Next() {
+			rows = append(rows, it.Value()["query"])
+		}
+		require.NoError(t, it.Err())
+	}
+	require.NoError(t, stmt.Close(context.Background()))
+	assert.Equal(t, []any{"SELECT 1", "SELECT 1"}, rows)
+	// the second execution does not send the binding of the first one
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assert.Equal(t, 1, f.bindings)
+}
+
+func TestFlightSQLCatalogStatsAndRetry(t *testing.T) {
+	client := startFlightServer(t, flightsql.NewFlightServer(&flightSQLServer{catalogFailures: 1}), useFlightSQL)
+
+	it, err := client.GetCatalogs(context.Background(), WithQueryRetry(QueryRetryOptions{
+		RetryOptions: RetryOptions{MaxAttempts: 2, InitialInterval: time.Millisecond},
+	}))
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	assert.Equal(t, "public", it.Value()["catalog_name"])
+	assert.False(t, it.Next())
+	require.NoError(t, it.Err())
+	stats := it.Stats()
+	assert.True(t, stats.Done)
+	assert.Equal(t, int64(1), stats.Rows)
+}
+
+func TestQueryFlightSQLInfluxQL(t *testing.T) {
+	client := startFlightServer(t, flightsql.NewFlightServer(&flightSQLServer{}), useFlightSQL)
+
+	_, err := client.Query(context.Background(), "SHOW MEASUREMENTS", WithQueryType(InfluxQL))
+	assert.EqualError(t, err, "InfluxQL queries are not supported by Flight SQL")
+}
+
+func TestFlightSQLDatabaseNotSet(t *testing.T) {
+	c, err := New(ClientConfig{
+		Host:  "http://localhost:8086",
+		Token: "my-token",
+	})
+	require.NoError(t, err)
+	_, err = c.Prepare(context.Background(), "SELECT 1")
+	assert.EqualError(t, err, "database not specified")
+	_, err = c.GetTables(context.Background(), GetTablesOptions{})
+	assert.EqualError(t, err, "database not specified")
+}
+
+func TestParametersRecord(t *testing.T) {
+	record, err := parametersRecord(nil, QueryParameters{"b": int32(1), "a": "x", "c": 1.5, "d": uint8(2), "e": true})
+	require.NoError(t, err)
+	defer record.Release()
+	require.Equal(t, int64(1), record.NumRows())
+	schema := record.Schema()
+	assert.Equal(t, "a", schema.Field(0).Name)
+	assert.Equal(t, "x", record.Column(0).(*array.String).Value(0))
+	assert.Equal(t, int64(1), record.Column(1).(*array.Int64).Value(0))
+	assert.InDelta(t, 1.5, record.Column(2).(*array.Float64).Value(0), 0)
+	assert.Equal(t, uint64(2), record.Column(3).(*array.Uint64).Value(0))
+	assert.True(t, record.Column(4).(*array.Boolean).Value(0))
+
+	// order of the statement parameter schema, names with $ prefix
+	paramSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "$z", Type: arrow.BinaryTypes.String},
+		{Name: "y", Type: arrow.BinaryTypes.String},
+	}, nil)
+	record2, err := parametersRecord(paramSchema, QueryParameters{"y": "1", "z": "2"})
+	require.NoError(t, err)
+	defer record2.Release()
+	assert.Equal(t, "2", record2.Column(0).(*array.String).Value(0))
+	assert.Equal(t, "1", record2.Column(1).(*array.String).Value(0))
+
+	_, err = parametersRecord(paramSchema, QueryParameters{"y": "1"})
+	assert.EqualError(t, err, "missing value of query parameter '$z'")
+	_, err = parametersRecord(nil, QueryParameters{"a": []int{1}})
+	assert.EqualError(t, err, "query parameter 'a': unsupported type []int")
+}
@@ -0,0 +1,251 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// defaultFollowInterval specifies the default value of FollowOptions.Interval.
+const defaultFollowInterval = time.Second
+
+// FollowOptions configures polling of Client.Follow.
+type FollowOptions struct {
+	// Interval between two polls.
+	// Default value: 1 second.
+	Interval time.Duration
+
+	// Start is the initial position of the time cursor.
+	// Default value: the time Follow is called.
+	Start time.Time
+
+	// Lookback is re-read before the time cursor by each poll, so that rows written late
+	// with a timestamp up to Lookback older than the newest row are delivered too.
+	// Rows older than Start are never delivered.
+	// Default value: 0.
+	Lookback time.Duration
+
+	// TimeColumn is the name of the timestamp column the cursor is tracked from.
+	// Default value: "time".
+	TimeColumn string
+}
+
+// FollowResult is a row or a failure delivered by Client.Follow.
+type FollowResult struct {
+	// Row is a new row as returned by QueryIterator.Value, nil when Err is set.
+	Row map[string]any
+	// Err is the error of a poll. Following continues with the next poll.
+	Err error
+}
+
+// Follow polls a query on an interval and delivers the rows written since the previous poll on the returned
+// channel, until ctx is canceled; the channel is closed then. The query must select the rows not older than
+// the $since parameter, which is set to an RFC3339 timestamp of the time cursor minus FollowOptions.Lookback
+// (not before FollowOptions.Start), e.g.
+//
+//	SELECT * FROM cpu WHERE time >= $since ORDER BY time
+//
+// The time cursor moves to the newest timestamp of the delivered rows. Rows at the cursor boundary and
+// within the lookback are read again by the next poll, rows already delivered are skipped.
+// Polling waits while the delivered rows are not received from the channel.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the requests, cancel it to stop following.
+//   - query: The query string to execute.
+//   - parameters: Other query parameters, can be nil.
+//   - followOptions: The polling options.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A channel of the new rows and poll errors.
+//   - An error, if any.
+func (c *Client) Follow(ctx context.Context, query string, parameters QueryParameters, followOptions FollowOptions,
+	options ...QueryOption) (<-chan FollowResult, error) {
+	if followOptions.Lookback < 0 {
+		return nil, errors.New("follow lookback must not be negative")
+	}
+	if followOptions.Interval <= 0 {
+		followOptions.Interval = defaultFollowInterval
+	}
+	if followOptions.Start.IsZero() {
+		followOptions.Start = time.Now()
+	}
+	if followOptions.TimeColumn == "" {
+		followOptions.TimeColumn = "time"
+	}
+
+	f := &follower{
+		c:          c,
+		query:      query,
+		parameters: parameters,
+		options:    followOptions,
+		opts:       newQueryOptions(&DefaultQueryOptions, options),
+		results:    make(chan FollowResult),
+		cursor:     followOptions.Start,
+		seen:       make(map[string]time.Time),
+	}
+	go f.run(ctx)
+	return f.results, nil
+}
+
+// follower holds the state of Client.Follow.
+type follower struct {
+	c          *Client
+	query      string
+	parameters QueryParameters
+	options    FollowOptions
+	opts       *QueryOptions
+	results    chan FollowResult
+	// newest timestamp of the delivered rows
+	cursor time.Time
+	// timestamps of the delivered rows within the lookback, by row key
+	seen map[string]time.Time
+	key  strings.Builder
+}
+
+func (f *follower) run(ctx context.Context) {
+	defer close(f.results)
+	ticker := time.NewTicker(f.options.Interval)
+	defer ticker.Stop()
+	for {
+		if err := f.poll(ctx); err != nil && !f.send(ctx, FollowResult{Err: err}) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll delivers the new rows of a single query.
+func (f *follower) poll(ctx context.Context) error {
+	since := f.cursor.Add(-f.options.Lookback)
+	if since.Before(f.options.Start) {
+		since = f.options.Start
+	}
+	params := maps.Clone(f.parameters)
+	if params == nil {
+		params = make(QueryParameters, 1)
+	}
+	params["since"] = since.UTC().Format(time.RFC3339Nano)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reader, err := f.c.getReader(ctx, f.query, params, f.opts)
+	if err != nil {
+		return err
+	}
+	defer releaseReader(reader)
+
+	cursor := f.cursor
+	for reader.Next() {
+		record := reader.RecordBatch()
+		ti := record.Schema().FieldIndices(f.options.TimeColumn)
+		if len(ti) == 0 {
+			return fmt.Errorf("time column '%s' not found", f.options.TimeColumn)
+		}
+		timestamps, ok := record.Column(ti[0]).(*array.Timestamp)
+		if !ok {
+			return fmt.Errorf("column '%s' is not a timestamp", f.options.TimeColumn)
+		}
+		unit := timestamps.DataType().(*arrow.TimestampType).Unit
+
+		for row := range int(record.NumRows()) {
+			if timestamps.IsNull(row) {
+				continue
+			}
+			t := timestamps.Value(row).ToTime(unit)
+			if t.Before(since) {
+				continue
+			}
+			key, err := f.rowKey(record, row)
+			if err != nil {
+				return err
+			}
+			if _, delivered := f.seen[key]; delivered {
+				continue
+			}
+			value, err := rowToMap(record.Schema(), record, row)
+			if err != nil {
+				return err
+			}
+			if !f.send(ctx, FollowResult{Row: value}) {
+				return nil
+			}
+			f.seen[key] = t
+			if t.After(cursor) {
+				cursor = t
+			}
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return err
+	}
+
+	// rows older than the next lookback are not read again
+	f.cursor = cursor
+	horizon := f.cursor.Add(-f.options.Lookback)
+	for key, t := range f.seen {
+		if t.Before(horizon) {
+			delete(f.seen, key)
+		}
+	}
+	return nil
+}
+
+// rowKey identifies a row by the values of all its columns.
+func (f *follower) rowKey(record arrow.RecordBatch, row int) (string, error) {
+	f.key.Reset()
+	schema := record.Schema()
+	for ci, col := range record.Columns() {
+		value, err := exportValue(col, schema.Field(ci), row)
+		if err != nil {
+			return "", err
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.UnixNano()
+		}
+		fmt.Fprint(&f.key, value)
+		f.key.WriteByte(0)
+	}
+	return f.key.String(), nil
+}
+
+func (f *follower) send(ctx context.Context, result FollowResult) bool {
+	select {
+	case f.results <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
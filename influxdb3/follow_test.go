@@ -0,0 +1,152 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type followRow struct {
+	time  time.Time
+	value string
+}
+
+// followFlightServer returns the rows not older than the since parameter.
+type followFlightServer struct {
+	flight.BaseFlightServer
+	mu   sync.Mutex
+	rows []followRow
+}
+
+func (f *followFlightServer) add(rows ...followRow) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows = append(f.rows, rows...)
+}
+
+func (f *followFlightServer) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
+	var ticket struct {
+		Params map[string]string `json:"params"`
+	}
+	if err := json.Unmarshal(tkt.GetTicket(), &ticket); err != nil {
+		return err
+	}
+	since, err := time.Parse(time.RFC3339Nano, ticket.Params["since"])
+	if err != nil {
+		return err
+	}
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+		{Name: "value", Type: arrow.BinaryTypes.String},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	f.mu.Lock()
+	for _, row := range f.rows {
+		if !row.time.Before(since) {
+			builder.Field(0).(*array.TimestampBuilder).Append(arrow.Timestamp(row.time.UnixNano()))
+			builder.Field(1).(*array.StringBuilder).Append(row.value)
+		}
+	}
+	f.mu.Unlock()
+	rec := builder.NewRecord()
+	defer rec.Release()
+
+	w := flight.NewRecordWriter(fs, ipc.WithSchema(schema))
+	return w.Write(rec)
+}
+
+func receiveFollow(t *testing.T, results <-chan FollowResult, n int) []string {
+	t.Helper()
+	values := make([]string, 0, n)
+	for len(values) < n {
+		select {
+		case result := <-results:
+			require.NoError(t, result.Err)
+			values = append(values, result.Row["value"].(string))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %v, expected %d rows", values, n)
+		}
+	}
+	return values
+}
+
+func TestFollow(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := &followFlightServer{}
+	server.add(
+		followRow{base.Add(-time.Second), "old"},
+		followRow{base.Add(time.Second), "a"},
+		followRow{base.Add(2 * time.Second), "b"},
+	)
+	client := startFlightServer(t, server, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := client.Follow(ctx, "SELECT * FROM t WHERE time >= $since ORDER BY time", nil, FollowOptions{
+		Interval: 10 * time.Millisecond,
+		Start:    base,
+		Lookback: 5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, receiveFollow(t, results, 2))
+
+	// rows at the cursor and late rows within the lookback are delivered once
+	server.add(
+		followRow{base.Add(2 * time.Second), "b2"},
+		followRow{base.Add(1500 * time.Millisecond), "late"},
+		followRow{base.Add(3 * time.Second), "c"},
+	)
+	assert.ElementsMatch(t, []string{"b2", "late", "c"}, receiveFollow(t, results, 3))
+
+	server.add(followRow{base.Add(4 * time.Second), "d"})
+	assert.Equal(t, []string{"d"}, receiveFollow(t, results, 1))
+
+	cancel()
+	for result := range results {
+		require.Nil(t, result.Row, "no rows are delivered again")
+	}
+}
+
+func TestFollowNegativeLookback(t *testing.T) {
+	client, err := New(ClientConfig{Host: "http://localhost:8086", Token: "my-token"})
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Follow(context.Background(), "SELECT 1", nil, FollowOptions{Lookback: -time.Second})
+	require.EqualError(t, err, "follow lookback must not be negative")
+}
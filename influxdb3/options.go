@@ -24,6 +24,7 @@ package influxdb3
 
 import (
 	"errors"
+	"fmt"
 	"maps"
 	"net/http"
 	"slices"
@@ -44,6 +45,14 @@ type QueryOptions struct {
 
 	// GRPC call options to be added
 	GrpcCallOptions []grpc.CallOption
+
+	// QueryConcurrency is the number of databases queried concurrently by Client.QueryMany.
+	// Default value: 4.
+	QueryConcurrency int
+
+	// QueryRetry is the retry policy of queries failing with transient gRPC errors,
+	// overrides QueryRetry of ClientConfig.
+	QueryRetry *QueryRetryOptions
 }
 
 // WriteOptions holds options for write
@@ -63,9 +72,13 @@ type WriteOptions struct {
 	// Remaining tags are serialized in deterministic lexicographic order.
 	TagOrder []string
 
-	// Write body larger than the threshold is gzipped. 0 for no compression.
+	// Write body larger than the threshold is compressed. 0 for no compression.
 	GzipThreshold int
 
+	// Compression selects the codec and level used to compress the write body.
+	// Default value: gzip with the default compression level
+	Compression Compression
+
 	// Instructs the server whether to wait with the response until WAL persistence completes.
 	// NoSync=true means faster write but without the confirmation that the data was persisted.
 	//
@@ -90,6 +103,31 @@ type WriteOptions struct {
 	// UseV2Api forces writes to the V2 API endpoint.
 	// Default value: true.
 	UseV2Api bool
+
+	// ResubmitValidLines controls recovery of writes rejected because of invalid lines
+	// when partial writes are disabled (AcceptPartial=false, UseV2Api=false).
+	// When true, the valid lines are written again without the rejected ones,
+	// and the returned *PartialWriteError describes the lines which were not written.
+	//
+	// Default value: false.
+	ResubmitValidLines bool
+
+	// ChunkSize is the maximum size in bytes of a request body written by Client.WriteFrom.
+	// Default value: 5 MiB.
+	ChunkSize int
+
+	// ChunkConcurrency is the number of chunks written concurrently by Client.WriteFrom.
+	// Default value: 1.
+	ChunkConcurrency int
+
+	// ChunkProgress is called by Client.WriteFrom after each successfully written chunk.
+	ChunkProgress func(WriteProgress)
+
+	// Retry defines how failed writes are repeated, for example when the server
+	// responds with 429 or 503. Retry-After sent by the server is honored.
+	//
+	// Default value: retrying disabled.
+	Retry RetryOptions
 }
 
 // DefaultQueryOptions specifies default query options
@@ -110,6 +148,9 @@ func (o *WriteOptions) validate() error {
 	if o.UseV2Api && o.NoSync {
 		return errors.New("invalid write options: NoSync requires UseV2Api=false")
 	}
+	if err := o.Compression.validate(); err != nil {
+		return fmt.Errorf("invalid write options: %w", err)
+	}
 	return nil
 }
 
@@ -122,6 +163,8 @@ type Option func(o *options)
 //   - WithQueryType
 //   - WithHeader
 //   - WithGrpcCallOption
+//   - WithQueryConcurrency
+//   - WithQueryRetry
 type QueryOption = Option
 
 // WriteOption is a functional option type that can be passed to Client.Write methods.
@@ -129,11 +172,17 @@ type QueryOption = Option
 //   - WithDatabase
 //   - WithPrecision
 //   - WithGzipThreshold
+//   - WithCompression
 //   - WithDefaultTags
 //   - WithTagOrder
 //   - WithNoSync
 //   - WithAcceptPartial
 //   - WithUseV2Api
+//   - WithWriteRetry
+//   - WithResubmitValidLines
+//   - WithChunkSize
+//   - WithChunkConcurrency
+//   - WithChunkProgress
 type WriteOption = Option
 
 // WithDatabase is used to override default database in Client.Query and Client.Write methods.
@@ -175,6 +224,15 @@ func WithGzipThreshold(gzipThreshold int) Option {
 	}
 }
 
+// WithCompression is used to select the codec and level compressing the write body in Client.Write methods.
+// The body is compressed only when it is larger than the gzip threshold. See Compression.Level for the levels,
+// e.g. gzip.DefaultCompression selects the default gzip level.
+func WithCompression(codec CompressionCodec, level int) Option {
+	return func(o *options) {
+		o.Compression = Compression{Codec: codec, Level: &level}
+	}
+}
+
 // WithDefaultTags is used to override default tags in Client.Write methods.
 func WithDefaultTags(tags map[string]string) Option {
 	return func(o *options) {
@@ -217,6 +275,50 @@ func WithUseV2Api(useV2Api bool) Option {
 	}
 }
 
+// WithResubmitValidLines overrides ResubmitValidLines in Client.Write methods.
+// This option applies only to writes with partial writes disabled (AcceptPartial=false)
+// sent to the V3 API endpoint (UseV2Api=false).
+func WithResubmitValidLines(resubmitValidLines bool) Option {
+	return func(o *options) {
+		o.ResubmitValidLines = resubmitValidLines
+	}
+}
+
+// WithChunkSize sets the maximum size in bytes of a request body written by Client.WriteFrom.
+func WithChunkSize(chunkSize int) Option {
+	return func(o *options) {
+		o.ChunkSize = chunkSize
+	}
+}
+
+// WithChunkConcurrency sets the number of chunks written concurrently by Client.WriteFrom.
+func WithChunkConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.ChunkConcurrency = concurrency
+	}
+}
+
+// WithChunkProgress sets the function called by Client.WriteFrom after each successfully written chunk.
+// Calls are serialized.
+func WithChunkProgress(f func(WriteProgress)) Option {
+	return func(o *options) {
+		o.ChunkProgress = f
+	}
+}
+
+// WithWriteRetry sets the retry policy applied to failed writes in Client.Write methods.
+// Use DefaultRetryOptions as a starting point:
+//
+//	retry := influxdb3.DefaultRetryOptions
+//	retry.MaxAttempts = 3
+//	err := client.WritePoints(ctx, points, influxdb3.WithWriteRetry(retry))
+func WithWriteRetry(retry RetryOptions) Option {
+	return func(o *options) {
+		o.Retry = retry
+		o.Retry.RetryableStatusCodes = slices.Clone(retry.RetryableStatusCodes)
+	}
+}
+
 // WithGrpcCallOption is used to send GRPC call options to the underlying Flight client
 //
 // Example:
@@ -233,6 +335,25 @@ func WithGrpcCallOption(grpcCallOption grpc.CallOption) Option {
 	}
 }
 
+// WithQueryConcurrency sets the number of databases queried concurrently by Client.QueryMany.
+func WithQueryConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.QueryConcurrency = concurrency
+	}
+}
+
+// WithQueryRetry sets the retry policy applied to queries failing with transient gRPC errors.
+// Use DefaultRetryOptions as a starting point:
+//
+//	retry := influxdb3.QueryRetryOptions{RetryOptions: influxdb3.DefaultRetryOptions}
+//	iterator, err := client.Query(ctx, query, influxdb3.WithQueryRetry(retry))
+func WithQueryRetry(retry QueryRetryOptions) Option {
+	return func(o *options) {
+		retry.RetryableCodes = slices.Clone(retry.RetryableCodes)
+		o.QueryRetry = &retry
+	}
+}
+
 type options struct {
 	QueryOptions
 	WriteOptions
@@ -61,6 +61,13 @@ func TestQueryOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "override query concurrency",
+			opts: va(WithQueryConcurrency(8)),
+			want: &QueryOptions{
+				QueryConcurrency: 8,
+			},
+		},
 		{
 			name: "add grpc option",
 			opts: va(WithGrpcCallOption(grpc.MaxCallRecvMsgSize(16)),
@@ -24,6 +24,7 @@ package influxdb3
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -48,6 +49,11 @@ type PartialWriteLineError struct {
 type PartialWriteError struct {
 	ServerError
 	LineErrors []PartialWriteLineError
+	// FailedInputs maps the rejected lines back to the inputs of WritePoints or WriteData which produced them,
+	// in the order of LineErrors. Points serialized to an empty line are skipped and never reported.
+	FailedInputs []PartialWriteFailedInput
+	// summary is the server error message without the line details
+	summary string
 }
 
 // Unwrap allows errors.As(err, &serverErr) where serverErr is *ServerError
@@ -59,6 +65,47 @@ func (e *PartialWriteError) Unwrap() error {
 	return &e.ServerError
 }
 
+// PartialWriteFailedInput describes an input of WritePoints or WriteData which produced a rejected line.
+type PartialWriteFailedInput struct {
+	// Index of the input in the slice passed to WritePoints or WriteData.
+	Index int
+	// Input is the *Point or the custom point which produced the line.
+	Input any
+	// LineError describes why the line failed.
+	LineError PartialWriteLineError
+}
+
+// withFailedInputs sets the FailedInputs of a *PartialWriteError in err.
+// lineInputs holds the index of the input for each line of the written payload.
+func withFailedInputs[T any](err error, lineInputs []int, inputs []T) error {
+	var partialErr *PartialWriteError
+	if !errors.As(err, &partialErr) {
+		return err
+	}
+	partialErr.FailedInputs = make([]PartialWriteFailedInput, 0, len(partialErr.LineErrors))
+	for _, lineError := range partialErr.LineErrors {
+		if lineError.LineNumber < 1 || lineError.LineNumber > len(lineInputs) {
+			continue
+		}
+		index := lineInputs[lineError.LineNumber-1]
+		partialErr.FailedInputs = append(partialErr.FailedInputs, PartialWriteFailedInput{
+			Index:     index,
+			Input:     inputs[index],
+			LineError: lineError,
+		})
+	}
+	return err
+}
+
+// partialWriteMessage formats the error message of the server message and the rejected lines.
+func partialWriteMessage(summary string, lineErrors []PartialWriteLineError) string {
+	details := formatPartialWriteLineErrorDetails(lineErrors)
+	if len(details) == 0 {
+		return summary
+	}
+	return summary + ":\n\t" + strings.Join(details, "\n\t")
+}
+
 func isPartialWriteMessage(message string) bool {
 	return strings.Contains(message, msgPartialWriteOccurred) ||
 		strings.Contains(message, msgParsingFailedLp39) ||
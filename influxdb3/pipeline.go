@@ -0,0 +1,330 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// defaultPipelineBatchSize specifies the default value of Pipeline.BatchSize.
+const defaultPipelineBatchSize = 1000
+
+// PipelineMapping describes how the rows of a query result are converted to points.
+type PipelineMapping struct {
+	// Measurement of the written points.
+	// Default value: the value of the "measurement" or "iox::measurement" column of each row.
+	Measurement string
+
+	// TimeColumn is the name of the timestamp column the timestamp of the points is read from,
+	// e.g. a DATE_BIN window column. Points without timestamp are written with the server time.
+	// Default value: "time".
+	TimeColumn string
+
+	// Tags maps the names of the columns written as tags to the tag names, an empty tag name keeps
+	// the column name. When nil, the columns with tag metadata are written as tags.
+	Tags map[string]string
+
+	// Fields maps the names of the columns written as fields to the field names, an empty field name keeps
+	// the column name. When nil, all columns other than tags, timestamps and the measurement are written as fields.
+	Fields map[string]string
+
+	// Exclude lists the columns which are not written.
+	Exclude []string
+}
+
+// Pipeline writes the result of a query as points, e.g. to downsample data or to copy data between databases.
+// A Pipeline can be run repeatedly, e.g. by a recurring rollup job.
+//
+//	pipeline := &influxdb3.Pipeline{
+//		Source: client,
+//		Query: `SELECT DATE_BIN(INTERVAL '5 minutes', time) AS window_start, location, AVG(temperature) AS avg
+//			FROM stat WHERE time >= now() - interval '1 hour' GROUP BY window_start, location`,
+//		Mapping: influxdb3.PipelineMapping{
+//			Measurement: "stat_downsampled",
+//			TimeColumn:  "window_start",
+//			Tags:        map[string]string{"location": ""},
+//		},
+//		Database: "downsampled",
+//	}
+//	written, err := pipeline.Run(ctx)
+type Pipeline struct {
+	// Source is the client executing the query.
+	Source *Client
+
+	// Query is the source query.
+	Query string
+
+	// Parameters of the source query, nil if the query is not parameterized.
+	Parameters QueryParameters
+
+	// QueryOptions are the options of the source query. See QueryOption for available options.
+	QueryOptions []QueryOption
+
+	// Mapping of the result rows to points.
+	Mapping PipelineMapping
+
+	// Transform is called with each point before it is written, e.g. to modify or to print it.
+	// The returned point is written, nil skips the row.
+	// Default value: nil (the points are written as mapped).
+	Transform func(*Point) *Point
+
+	// Destination is the client writing the points.
+	// Default value: Source.
+	Destination *Client
+
+	// Database the points are written to.
+	// Default value: the database of the write options of Destination.
+	Database string
+
+	// WriteOptions are the options of the writes. See WriteOption for available options.
+	WriteOptions []WriteOption
+
+	// BatchSize is the number of points written by a single request.
+	// Default value: 1000.
+	BatchSize int
+}
+
+// Run executes the query and writes its result in batches of points.
+// Rows without any field value are skipped, as they cannot be written.
+// The points written before a failure are not rolled back.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the query and the writes.
+//
+// Returns:
+//   - The number of written points.
+//   - An error, if any.
+func (p *Pipeline) Run(ctx context.Context) (int, error) {
+	if p.Source == nil {
+		return 0, errors.New("pipeline source client not specified")
+	}
+	destination := p.Destination
+	if destination == nil {
+		destination = p.Source
+	}
+	writeOptions := p.WriteOptions
+	if p.Database != "" {
+		writeOptions = append(writeOptions[:len(writeOptions):len(writeOptions)], WithDatabase(p.Database))
+	}
+	batchSize := p.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPipelineBatchSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reader, err := p.Source.getReader(ctx, p.Query, p.Parameters, newQueryOptions(&DefaultQueryOptions, p.QueryOptions))
+	if err != nil {
+		return 0, err
+	}
+	defer releaseReader(reader)
+
+	written := 0
+	batch := make([]*Point, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := destination.WritePoints(ctx, batch, writeOptions...); err != nil {
+			return err
+		}
+		written += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	var schema *arrow.Schema
+	var plan *pipelinePlan
+	for reader.Next() {
+		record := reader.RecordBatch()
+		if schema == nil || !schema.Equal(record.Schema()) {
+			schema = record.Schema()
+			if plan, err = p.Mapping.plan(schema); err != nil {
+				return written, err
+			}
+		}
+		for row := range int(record.NumRows()) {
+			point, err := plan.point(record, row)
+			if err != nil {
+				return written, fmt.Errorf("row %d: %w", row, err)
+			}
+			if p.Transform != nil {
+				if point = p.Transform(point); point == nil {
+					continue
+				}
+			}
+			if !point.HasFields() {
+				continue
+			}
+			batch = append(batch, point)
+			if len(batch) >= batchSize {
+				if err := flush(); err != nil {
+					return written, err
+				}
+			}
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return written, err
+	}
+	if err := flush(); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+// pipelineColumn is a column written as a tag or a field.
+type pipelineColumn struct {
+	index int
+	name  string
+}
+
+// pipelinePlan converts the rows of a schema to points.
+type pipelinePlan struct {
+	schema      *arrow.Schema
+	measurement string
+	// columns of the measurement and of the timestamp, -1 if none
+	measurementColumn int
+	timeColumn        int
+	tags              []pipelineColumn
+	fields            []pipelineColumn
+}
+
+// plan resolves the mapping for the columns of schema.
+func (m *PipelineMapping) plan(schema *arrow.Schema) (*pipelinePlan, error) {
+	p := &pipelinePlan{
+		schema:            schema,
+		measurement:       m.Measurement,
+		measurementColumn: -1,
+		timeColumn:        -1,
+	}
+	timeColumn := m.TimeColumn
+	if timeColumn == "" {
+		timeColumn = "time"
+	}
+
+	for i, f := range schema.Fields() {
+		name := f.Name
+		tag, isTag := m.Tags[name]
+		field, isField := m.Fields[name]
+		switch {
+		case slices.Contains(m.Exclude, name):
+		case name == timeColumn:
+			if f.Type.ID() != arrow.TIMESTAMP {
+				return nil, fmt.Errorf("time column '%s' is not a timestamp", name)
+			}
+			p.timeColumn = i
+		case isTag:
+			p.tags = append(p.tags, pipelineColumn{index: i, name: orName(tag, name)})
+		case isField:
+			p.fields = append(p.fields, pipelineColumn{index: i, name: orName(field, name)})
+		case name == "measurement" || name == "iox::measurement":
+			p.measurementColumn = i
+		case m.Tags == nil && columnRole(f) == ColumnRoleTag:
+			p.tags = append(p.tags, pipelineColumn{index: i, name: name})
+		case m.Fields == nil && columnRole(f) != ColumnRoleTag && columnRole(f) != ColumnRoleTimestamp:
+			p.fields = append(p.fields, pipelineColumn{index: i, name: name})
+		}
+	}
+
+	if m.TimeColumn != "" && p.timeColumn < 0 {
+		return nil, fmt.Errorf("time column '%s' not found in query result", m.TimeColumn)
+	}
+	for _, columns := range []map[string]string{m.Tags, m.Fields} {
+		for name := range columns {
+			if len(schema.FieldIndices(name)) == 0 {
+				return nil, fmt.Errorf("column '%s' not found in query result", name)
+			}
+		}
+	}
+	if p.measurement == "" && p.measurementColumn < 0 {
+		return nil, errors.New("no measurement: set PipelineMapping.Measurement or select a measurement column")
+	}
+	return p, nil
+}
+
+// point converts a row to a point.
+func (p *pipelinePlan) point(record arrow.RecordBatch, row int) (*Point, error) {
+	measurement := p.measurement
+	if measurement == "" {
+		value, err := p.value(record, p.measurementColumn, row)
+		if err != nil {
+			return nil, err
+		}
+		measurement, _ = value.(string)
+		if measurement == "" {
+			return nil, errors.New("no measurement")
+		}
+	}
+
+	point := NewPointWithMeasurement(measurement)
+	if p.timeColumn >= 0 {
+		value, err := p.value(record, p.timeColumn, row)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := value.(time.Time); ok {
+			point.SetTimestamp(t)
+		}
+	}
+	for _, tag := range p.tags {
+		value, err := p.value(record, tag.index, row)
+		if err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case nil:
+		case string:
+			point.SetTag(tag.name, v)
+		default:
+			point.SetTag(tag.name, fmt.Sprint(v))
+		}
+	}
+	for _, field := range p.fields {
+		value, err := p.value(record, field.index, row)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			point.SetField(field.name, value)
+		}
+	}
+	return point, nil
+}
+
+func (p *pipelinePlan) value(record arrow.RecordBatch, column int, row int) (any, error) {
+	return exportValue(record.Column(column), p.schema.Field(column), row)
+}
+
+func orName(name string, column string) string {
+	if name == "" {
+		return column
+	}
+	return name
+}
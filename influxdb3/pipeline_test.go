@@ -0,0 +1,180 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startWriteServer returns a client writing to a server recording the written lines and databases.
+func startWriteServer(t *testing.T) (*Client, func() ([]string, []string)) {
+	t.Helper()
+	var mu sync.Mutex
+	var lines, databases []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		database := r.URL.Query().Get("db")
+		if database == "" {
+			database = r.URL.Query().Get("bucket")
+		}
+		mu.Lock()
+		lines = append(lines, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+		databases = append(databases, database)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client, func() ([]string, []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(lines), slices.Clone(databases)
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+	source := startFlightServer(t, &flightServer{}, nil)
+	destination, written := startWriteServer(t)
+
+	pipeline := &Pipeline{
+		Source: source,
+		Query:  "SELECT intField, stringField, floatField FROM data",
+		Mapping: PipelineMapping{
+			Measurement: "copy",
+			Tags:        map[string]string{"stringField": "name"},
+			Fields:      map[string]string{"intField": "count", "floatField": ""},
+		},
+		Destination: destination,
+		Database:    "rollups",
+		BatchSize:   2,
+	}
+	n, err := pipeline.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	lines, databases := written()
+	assert.Equal(t, []string{
+		"copy,name=a count=1i,floatField=1",
+		"copy,name=b count=2i",
+		"copy,name=c count=3i,floatField=3",
+		"copy,name=d count=4i",
+		"copy,name=e count=5i,floatField=5",
+	}, lines)
+	assert.Equal(t, []string{"rollups", "rollups", "rollups"}, databases)
+}
+
+func TestPipelineTransform(t *testing.T) {
+	source := startFlightServer(t, &flightServer{}, nil)
+	destination, written := startWriteServer(t)
+
+	pipeline := &Pipeline{
+		Source: source,
+		Query:  "SELECT intField, stringField FROM data",
+		Mapping: PipelineMapping{
+			Measurement: "copy",
+			Fields:      map[string]string{"intField": "count"},
+		},
+		Transform: func(point *Point) *Point {
+			if *point.GetIntegerField("count")%2 == 0 {
+				return nil
+			}
+			return point.SetTag("odd", "true")
+		},
+		Destination: destination,
+	}
+	n, err := pipeline.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	lines, _ := written()
+	assert.Equal(t, []string{
+		"copy,odd=true count=1i",
+		"copy,odd=true count=3i",
+		"copy,odd=true count=5i",
+	}, lines)
+}
+
+func TestPipelineMappingPlan(t *testing.T) {
+	tag := arrow.NewMetadata([]string{"iox::column::type"}, []string{"iox::column_type::tag"})
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "iox::measurement", Type: arrow.BinaryTypes.String},
+		{Name: "window_start", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+		{Name: "location", Type: arrow.BinaryTypes.String, Metadata: tag},
+		{Name: "region", Type: arrow.BinaryTypes.String, Metadata: tag},
+		{Name: "avg", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "max", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+
+	mapping := PipelineMapping{TimeColumn: "window_start", Exclude: []string{"region", "max"}}
+	plan, err := mapping.plan(schema)
+	require.NoError(t, err)
+	assert.Equal(t, 0, plan.measurementColumn)
+	assert.Equal(t, 1, plan.timeColumn)
+	assert.Equal(t, []pipelineColumn{{index: 2, name: "location"}}, plan.tags)
+	assert.Equal(t, []pipelineColumn{{index: 4, name: "avg"}}, plan.fields)
+
+	mapping = PipelineMapping{TimeColumn: "missing"}
+	_, err = mapping.plan(schema)
+	require.EqualError(t, err, "time column 'missing' not found in query result")
+
+	mapping = PipelineMapping{TimeColumn: "avg"}
+	_, err = mapping.plan(schema)
+	require.EqualError(t, err, "time column 'avg' is not a timestamp")
+
+	mapping = PipelineMapping{Fields: map[string]string{"min": ""}}
+	_, err = mapping.plan(schema)
+	require.EqualError(t, err, "column 'min' not found in query result")
+
+	_, err = (&PipelineMapping{}).plan(arrow.NewSchema([]arrow.Field{{Name: "f", Type: arrow.PrimitiveTypes.Float64}}, nil))
+	require.Error(t, err)
+}
+
+func TestPipelineNoSource(t *testing.T) {
+	_, err := (&Pipeline{Query: "SELECT 1"}).Run(context.Background())
+	require.EqualError(t, err, "pipeline source client not specified")
+}
@@ -263,6 +263,24 @@ func (p *Point) MarshalBinaryWithDefaultTags(precision Precision, defaultTags ma
 	return p.marshalBinaryWithOptions(precision, defaultTags, nil)
 }
 
+// MarshalBinaryWithOptions converts the Point to its binary representation in line protocol format
+// the same way as Client.WritePoints does.
+//
+// Parameters:
+//   - options: Write options providing the precision, default tags and tag order. If nil, DefaultWriteOptions is used.
+//
+// Returns:
+//   - The binary representation of the Point in line protocol format.
+//   - An error, if any.
+//
+// Field filtering behavior is the same as MarshalBinary.
+func (p *Point) MarshalBinaryWithOptions(options *WriteOptions) ([]byte, error) {
+	if options == nil {
+		options = &DefaultWriteOptions
+	}
+	return p.marshalBinaryWithOptions(options.Precision, options.DefaultTags, options.TagOrder)
+}
+
 // WithFieldConverter sets a custom field converter function for transforming field values when used.
 func (p *Point) WithFieldConverter(converter func(any) any) {
 	p.fieldConverter = converter
@@ -618,6 +618,27 @@ func TestPointTagOrderWithDefaultTags(t *testing.T) {
 	assert.EqualValues(t, "test,region=us-east,host=h1,rack=r1,zone=z1 field=1i 60000000070\n", string(line))
 }
 
+func TestPointMarshalBinaryWithOptions(t *testing.T) {
+	p := NewPoint("test", map[string]string{
+		"host":   "h1",
+		"region": "us-east",
+	}, map[string]any{
+		"field": 1,
+	}, time.Unix(60, 70))
+
+	line, err := p.MarshalBinaryWithOptions(&WriteOptions{
+		Precision:   Second,
+		DefaultTags: map[string]string{"rack": "r1"},
+		TagOrder:    []string{"region"},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, "test,region=us-east,host=h1,rack=r1 field=1i 60\n", string(line))
+
+	line, err = p.MarshalBinaryWithOptions(nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, "test,host=h1,region=us-east field=1i 60000000070\n", string(line))
+}
+
 func TestPointNilAndEmptyDefaultTagsSameOutput(t *testing.T) {
 	p := NewPoint("test", map[string]string{
 		"tag1": "a",
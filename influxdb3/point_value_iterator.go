@@ -41,6 +41,8 @@ type PointValueIterator struct {
 	index int
 	// Current record
 	record arrow.RecordBatch
+	// Closed by Close
+	closed bool
 }
 
 // NewPointValueIterator return a new PointValueIterator instance
@@ -73,6 +75,9 @@ func NewPointValueIteratorFomReader(reader RecordReader) *PointValueIterator {
 //		process(PointValue)
 //	}
 func (it *PointValueIterator) Next() (*PointValues, error) {
+	if it.closed {
+		return nil, Done
+	}
 	it.index++
 
 	for it.record == nil || it.index >= int(it.record.NumRows()) {
@@ -94,3 +99,23 @@ func (it *PointValueIterator) Next() (*PointValues, error) {
 func (it *PointValueIterator) Index() int {
 	return it.index
 }
+
+// Close cancels the query and releases the received data. Next returns Done once the
+// iterator is closed. It is safe to call Close more than once, or after the result is read completely.
+//
+// Returns:
+//   - Always nil, the error of the query is returned by Next.
+func (it *PointValueIterator) Close() error {
+	it.closed = true
+	it.record = nil
+	if it.reader != nil {
+		releaseReader(it.reader)
+	}
+	return nil
+}
+
+// Stats returns the statistics of the query, see QueryStats.
+// The statistics are empty when the iterator was not created by a Client query method.
+func (it *PointValueIterator) Stats() QueryStats {
+	return readerStats(it.reader)
+}
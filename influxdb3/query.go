@@ -28,15 +28,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/flight"
 	"github.com/apache/arrow-go/v18/arrow/ipc"
-	"github.com/apache/arrow-go/v18/arrow/memory"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
@@ -45,20 +47,8 @@ import (
 )
 
 func (c *Client) initializeQueryClient(hostPortURL string, secure bool, proxyURL *url.URL) error {
-	var transport grpc.DialOption
-
-	if secure {
-		var tlsConfig *tls.Config
-		if transport, ok := c.config.HTTPClient.Transport.(*http.Transport); ok {
-			tlsConfig = transport.TLSClientConfig
-		}
-		transport = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
-	} else {
-		transport = grpc.WithTransportCredentials(insecure.NewCredentials())
-	}
-
 	opts := []grpc.DialOption{
-		transport,
+		c.queryTransport(secure),
 	}
 
 	if proxyURL != nil {
@@ -88,6 +78,18 @@ func (c *Client) initializeQueryClient(hostPortURL string, secure bool, proxyURL
 	return nil
 }
 
+// queryTransport returns the transport credentials of Flight clients, TLS uses the configuration of the HTTP client.
+func (c *Client) queryTransport(secure bool) grpc.DialOption {
+	if !secure {
+		return grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+	var tlsConfig *tls.Config
+	if transport, ok := c.config.HTTPClient.Transport.(*http.Transport); ok {
+		tlsConfig = transport.TLSClientConfig
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+}
+
 func (c *Client) setQueryClient(flightClient flight.Client) {
 	c.queryClient = flightClient
 }
@@ -170,6 +172,130 @@ func (c *Client) QueryWithOptions(ctx context.Context, options *QueryOptions, qu
 	return c.query(ctx, query, nil, options)
 }
 
+// QueryRows queries data from InfluxDB v3 and returns an iterator over the result rows,
+// see QueryIterator.Value for the types of values.
+// An error of the query or of reading the response is yielded as the last element.
+// Breaking the loop early cancels the query and releases the received data.
+//
+//	for row, err := range client.QueryRows(ctx, query) {
+//		if err != nil {
+//			return err
+//		}
+//		process(row)
+//	}
+//
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - query: The query string to execute.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - An iterator of rows (map[string]any) and errors.
+func (c *Client) QueryRows(ctx context.Context, query string, options ...QueryOption) iter.Seq2[map[string]any, error] {
+	return c.QueryRowsWithParameters(ctx, query, nil, options...)
+}
+
+// QueryRowsWithParameters queries data from InfluxDB v3 with parameterized query
+// and returns an iterator over the result rows, see QueryRows.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - query: The query string to execute.
+//   - parameters: The query parameters.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - An iterator of rows (map[string]any) and errors.
+func (c *Client) QueryRowsWithParameters(ctx context.Context, query string, parameters QueryParameters,
+	options ...QueryOption) iter.Seq2[map[string]any, error] {
+	return querySeq(ctx, c, query, parameters, newQueryOptions(&DefaultQueryOptions, options),
+		func(schema *arrow.Schema, record arrow.RecordBatch, row int) (map[string]any, error) {
+			return rowToMap(schema, record, row)
+		})
+}
+
+// QueryPoints queries data from InfluxDB v3 and returns an iterator over the result rows
+// as *PointValues. An error of the query or of reading the response is yielded as the last element.
+// Breaking the loop early cancels the query and releases the received data.
+//
+//	for point, err := range client.QueryPoints(ctx, query) {
+//		if err != nil {
+//			return err
+//		}
+//		process(point)
+//	}
+//
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - query: The query string to execute.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - An iterator of rows (*PointValues) and errors.
+func (c *Client) QueryPoints(ctx context.Context, query string, options ...QueryOption) iter.Seq2[*PointValues, error] {
+	return c.QueryPointsWithParameters(ctx, query, nil, options...)
+}
+
+// QueryPointsWithParameters queries data from InfluxDB v3 with parameterized query
+// and returns an iterator over the result rows as *PointValues, see QueryPoints.
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - query: The query string to execute.
+//   - parameters: The query parameters.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - An iterator of rows (*PointValues) and errors.
+func (c *Client) QueryPointsWithParameters(ctx context.Context, query string, parameters QueryParameters,
+	options ...QueryOption) iter.Seq2[*PointValues, error] {
+	return querySeq(ctx, c, query, parameters, newQueryOptions(&DefaultQueryOptions, options),
+		func(_ *arrow.Schema, record arrow.RecordBatch, row int) (*PointValues, error) {
+			return rowToPointValueWithError(record, row)
+		})
+}
+
+// querySeq returns an iterator executing the query on each iteration and yielding
+// the rows converted by value. The query stream is canceled and the reader released
+// when the iteration stops.
+func querySeq[T any](ctx context.Context, c *Client, query string, parameters QueryParameters, options *QueryOptions,
+	value func(*arrow.Schema, arrow.RecordBatch, int) (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		reader, err := c.getReader(ctx, query, parameters, options)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer releaseReader(reader)
+
+		for reader.Next() {
+			record := reader.RecordBatch()
+			for row := range int(record.NumRows()) {
+				v, err := value(reader.Schema(), record, row)
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+		if err := reader.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// releaseReader releases the resources held by reader, if supported.
+func releaseReader(reader RecordReader) {
+	if r, ok := reader.(interface{ Release() }); ok {
+		r.Release()
+	}
+}
+
 func (c *Client) query(ctx context.Context, query string, parameters QueryParameters, options *QueryOptions) (*QueryIterator, error) {
 	reader, err := c.getReader(ctx, query, parameters, options)
 	if err != nil {
@@ -188,33 +314,50 @@ func (c *Client) queryPointValue(ctx context.Context, query string, parameters Q
 	return NewPointValueIteratorFomReader(reader), nil
 }
 
+// getReader executes the query and returns a reader of its result collecting QueryStats.
 func (c *Client) getReader(ctx context.Context, query string, parameters QueryParameters, options *QueryOptions) (RecordReader, error) { //nolint:ireturn
-	var database string
-	if options.Database != "" {
-		database = options.Database
+	return c.openReader(ctx, options, func(opts *QueryOptions) (RecordReader, error) {
+		return c.getStreamReader(ctx, query, parameters, opts)
+	})
+}
+
+// openReader opens a query result by open, collecting QueryStats and retrying according to the query retry policy.
+// open receives a copy of options with the call options collecting the response headers and trailers.
+func (c *Client) openReader(ctx context.Context, options *QueryOptions,
+	open func(*QueryOptions) (RecordReader, error)) (RecordReader, error) { //nolint:ireturn
+	stats := newStatsRecordReader()
+	opts := *options
+	opts.GrpcCallOptions = append(slices.Clip(options.GrpcCallOptions), grpc.Header(&stats.header), grpc.Trailer(&stats.trailer))
+
+	var reader RecordReader
+	var err error
+	if retry := c.queryRetryOptions(options); retry != nil {
+		reader, err = openWithRetry(ctx, retry, func() (RecordReader, error) {
+			return open(&opts)
+		})
 	} else {
-		database = c.config.Database
+		reader, err = open(&opts)
 	}
-	if database == "" {
-		return nil, errors.New("database not specified")
+	if err != nil {
+		return nil, err
+	}
+	stats.reader = reader
+	return stats, nil
+}
+
+func (c *Client) getStreamReader(ctx context.Context, query string, parameters QueryParameters, options *QueryOptions) (RecordReader, error) { //nolint:ireturn
+	database, err := c.queryDatabase(options)
+	if err != nil {
+		return nil, err
 	}
 
 	var queryType = options.QueryType
 
-	md := make(metadata.MD, 0)
-	for k, v := range c.config.Headers {
-		for _, value := range v {
-			md.Append(k, value)
-		}
-	}
-	for k, v := range options.Headers {
-		for _, value := range v {
-			md.Append(k, value)
-		}
+	ctx = c.queryContext(ctx, options)
+
+	if c.config.UseFlightSQL {
+		return c.getFlightSQLReader(ctx, database, query, parameters, options)
 	}
-	md.Set("authorization", "Bearer "+c.config.Token)
-	md.Set("User-Agent", userAgent)
-	ctx = metadata.NewOutgoingContext(ctx, md)
 
 	ticketData := map[string]any{
 		"database":   database,
@@ -231,40 +374,61 @@ func (c *Client) getReader(ctx context.Context, query string, parameters QueryPa
 		return nil, fmt.Errorf("serialize: %w", err)
 	}
 
-	ticket := &flight.Ticket{Ticket: ticketJSON}
-
 	grpcCallOptions := make([]grpc.CallOption, 0)
 	if options.GrpcCallOptions != nil {
 		grpcCallOptions = append(grpcCallOptions, options.GrpcCallOptions...)
 	}
 
-	var _ctx context.Context
-	var cancel context.CancelFunc
-
-	if c.config.QueryTimeout > 0 {
-		_ctx, cancel = context.WithTimeout(ctx, c.config.QueryTimeout)
-	} else {
-		_ctx = ctx
+	if c.config.UseFlightInfo {
+		return c.getFlightInfoReader(ctx, ticketJSON, grpcCallOptions)
 	}
 
+	ticket := &flight.Ticket{Ticket: ticketJSON}
+
+	// the context is canceled when the stream ends or the reader is released
+	_ctx, cancel := c.queryTimeoutContext(ctx)
+
 	stream, err := c.queryClient.DoGet(_ctx, ticket, grpcCallOptions...)
 	if err != nil {
-		if cancel != nil {
-			cancel()
-		}
+		cancel()
 		return nil, fmt.Errorf("flight do get: %w", err)
 	}
 
-	reader, err := flight.NewRecordReader(stream, ipc.WithAllocator(memory.DefaultAllocator))
+	reader, err := flight.NewRecordReader(stream, ipc.WithAllocator(c.allocator))
 	if err != nil {
-		if cancel != nil {
-			cancel()
-		}
+		cancel()
 		return nil, fmt.Errorf("flight reader: %w", err)
 	}
 
-	if cancel == nil {
-		return reader, nil
-	}
 	return &cancelingRecordReader{reader: reader, cancel: cancel}, nil
 }
+
+// queryDatabase returns the database of a query, options override the client configuration.
+func (c *Client) queryDatabase(options *QueryOptions) (string, error) {
+	database := c.config.Database
+	if options.Database != "" {
+		database = options.Database
+	}
+	if database == "" {
+		return "", errors.New("database not specified")
+	}
+	return database, nil
+}
+
+// queryContext returns ctx with the gRPC metadata of a query request.
+func (c *Client) queryContext(ctx context.Context, options *QueryOptions) context.Context {
+	md := make(metadata.MD, 0)
+	for k, v := range c.config.Headers {
+		for _, value := range v {
+			md.Append(k, value)
+		}
+	}
+	for k, v := range options.Headers {
+		for _, value := range v {
+			md.Append(k, value)
+		}
+	}
+	md.Set("authorization", "Bearer "+c.config.Token)
+	md.Set("User-Agent", userAgent)
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -0,0 +1,346 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/float16"
+)
+
+// QueryAs queries data from InfluxDB v3 and decodes each row into a value of type T.
+// T must be a struct annotated with 'lp' tags the same way as points passed to WriteData,
+// so that one type can be used both for writing and reading:
+//   - measurement is read from the "measurement" or "iox::measurement" column
+//   - timestamp is read from the "time" column
+//   - tag and field are read from the column named by the struct field
+//
+// The column name can be overridden by the second tag attribute, e.g. `lp:"field,usage_user"`.
+// Columns without a matching struct field are ignored, NULL values leave the struct field unset.
+//
+// Arrow timestamps are converted to time.Time, numeric values are converted to any numeric
+// struct field that can hold them (e.g. int64 to float64, or int64 to int32 when it does not overflow).
+// Other type mismatches are reported as an error by the iterator.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - c: The client used to execute the query.
+//   - query: The query string to execute.
+//   - parameters: The query parameters, nil if the query is not parameterized.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A result iterator (*TypedQueryIterator[T]).
+//   - An error, if any.
+func QueryAs[T any](ctx context.Context, c *Client, query string, parameters QueryParameters,
+	options ...QueryOption) (*TypedQueryIterator[T], error) {
+	if _, err := structFields(reflect.TypeFor[T]()); err != nil {
+		return nil, err
+	}
+	reader, err := c.getReader(ctx, query, parameters, newQueryOptions(&DefaultQueryOptions, options))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTypedQueryIteratorFromReader[T](reader)
+}
+
+// TypedQueryIterator is a query iterator decoding each row into a value of type T,
+// see QueryAs for the decoding rules.
+type TypedQueryIterator[T any] struct {
+	reader RecordReader
+	// Index of row of current object in current record
+	index int
+	// Current record
+	record arrow.RecordBatch
+	// Struct fields of T that can be decoded
+	fields []structField
+	// Decoder of the current record schema
+	schema  *arrow.Schema
+	columns []columnDecoder
+	// Closed by Close
+	closed bool
+}
+
+// NewTypedQueryIteratorFromReader returns a new TypedQueryIterator from a RecordReader.
+// It fails when T is not a struct with valid 'lp' tags.
+func NewTypedQueryIteratorFromReader[T any](reader RecordReader) (*TypedQueryIterator[T], error) {
+	fields, err := structFields(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return &TypedQueryIterator[T]{
+		reader: reader,
+		index:  -1,
+		fields: fields,
+	}, nil
+}
+
+// Next returns the next result.
+// Its second return value is iterator.Done if there are no more results.
+// Once Next returns Done in the second parameter, all subsequent calls will return Done.
+//
+//	it, err := influxdb3.QueryAs[Measurement](ctx, client, query, nil)
+//	if err != nil {
+//		return err
+//	}
+//	for {
+//		m, err := it.Next()
+//		if err == influxdb3.Done {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		process(m)
+//	}
+func (it *TypedQueryIterator[T]) Next() (T, error) {
+	var value T
+	if it.closed {
+		return value, Done
+	}
+	it.index++
+
+	for it.record == nil || it.index >= int(it.record.NumRows()) {
+		if !it.reader.Next() {
+			if err := it.reader.Err(); err != nil {
+				return value, err
+			}
+			return value, Done
+		}
+		it.record = it.reader.RecordBatch()
+		it.index = 0
+	}
+
+	if schema := it.record.Schema(); it.schema == nil || !it.schema.Equal(schema) {
+		it.schema = schema
+		it.columns = it.bindColumns(schema)
+	}
+
+	v := reflect.ValueOf(&value).Elem()
+	for _, col := range it.columns {
+		field := it.schema.Field(col.column)
+		raw, _, err := getArrowValue(it.record.Column(col.column), field, it.index)
+		if err != nil {
+			return value, fmt.Errorf("column '%s': %w", field.Name, err)
+		}
+		if raw == nil {
+			continue
+		}
+		if err := decodeValue(v.FieldByIndex(col.field.index), raw, field); err != nil {
+			return value, fmt.Errorf("cannot decode column '%s' into field '%s': %w", field.Name, col.field.name, err)
+		}
+	}
+
+	return value, nil
+}
+
+// Index return the current index of TypedQueryIterator
+func (it *TypedQueryIterator[T]) Index() int {
+	return it.index
+}
+
+// Close cancels the query and releases the received data. Next returns Done once the
+// iterator is closed. It is safe to call Close more than once.
+func (it *TypedQueryIterator[T]) Close() error {
+	it.closed = true
+	it.record = nil
+	releaseReader(it.reader)
+	return nil
+}
+
+// bindColumns matches the schema columns to the struct fields.
+func (it *TypedQueryIterator[T]) bindColumns(schema *arrow.Schema) []columnDecoder {
+	columns := make([]columnDecoder, 0, len(it.fields))
+	for _, f := range it.fields {
+		for _, name := range f.columns {
+			if indices := schema.FieldIndices(name); len(indices) > 0 {
+				columns = append(columns, columnDecoder{column: indices[0], field: f})
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// structField is a struct field decoded from one of the columns.
+type structField struct {
+	name    string
+	index   []int
+	columns []string
+}
+
+type columnDecoder struct {
+	column int
+	field  structField
+}
+
+// structFields returns the fields of t annotated with 'lp' tags.
+func structFields(t reflect.Type) ([]structField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot decode into %v, struct expected", t)
+	}
+
+	var fields []structField
+	for _, f := range reflect.VisibleFields(t) {
+		tag, ok := f.Tag.Lookup("lp")
+		if !ok || tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if len(parts) > 2 {
+			return nil, errors.New("multiple tag attributes are not supported")
+		}
+		typ, name := parts[0], f.Name
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		var columns []string
+		switch typ {
+		case "measurement":
+			columns = []string{"measurement", "iox::measurement"}
+		case "tag", "field":
+			columns = []string{name}
+		case "timestamp":
+			if f.Type != timeType {
+				return nil, fmt.Errorf("cannot use field '%s' as a timestamp", f.Name)
+			}
+			columns = []string{"time"}
+			if len(parts) == 2 {
+				columns = []string{name}
+			}
+		default:
+			return nil, fmt.Errorf("invalid tag %s", typ)
+		}
+		if !f.IsExported() {
+			return nil, fmt.Errorf("cannot decode into unexported field '%s'", f.Name)
+		}
+		fields = append(fields, structField{name: f.Name, index: f.Index, columns: columns})
+	}
+	return fields, nil
+}
+
+// decodeValue sets v to the value read from a column, converting it to the type of v.
+func decodeValue(v reflect.Value, value any, field arrow.Field) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	// normalize Arrow specific types
+	switch x := value.(type) {
+	case arrow.Timestamp:
+		unit := arrow.Nanosecond
+		if tt, ok := field.Type.(*arrow.TimestampType); ok {
+			unit = tt.Unit
+		}
+		value = x.ToTime(unit)
+	case float16.Num:
+		value = x.Float32()
+	}
+
+	if v.Type() == timeType {
+		t, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to time.Time", value)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Interface:
+		if rv.Type().Implements(v.Type()) {
+			v.Set(rv)
+			return nil
+		}
+	case reflect.String:
+		if rv.Kind() == reflect.String {
+			v.SetString(rv.String())
+			return nil
+		}
+	case reflect.Bool:
+		if rv.Kind() == reflect.Bool {
+			v.SetBool(rv.Bool())
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch {
+		case rv.CanInt():
+			i = rv.Int()
+		case rv.CanUint() && rv.Uint() <= 1<<63-1:
+			i = int64(rv.Uint()) //nolint:gosec
+		default:
+			return fmt.Errorf("cannot convert %T to %v", value, v.Type())
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("value %v overflows %v", value, v.Type())
+		}
+		v.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch {
+		case rv.CanUint():
+			u = rv.Uint()
+		case rv.CanInt() && rv.Int() >= 0:
+			u = uint64(rv.Int())
+		default:
+			return fmt.Errorf("cannot convert %T to %v", value, v.Type())
+		}
+		if v.OverflowUint(u) {
+			return fmt.Errorf("value %v overflows %v", value, v.Type())
+		}
+		v.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch {
+		case rv.CanFloat():
+			f = rv.Float()
+		case rv.CanInt():
+			f = float64(rv.Int())
+		case rv.CanUint():
+			f = float64(rv.Uint())
+		default:
+			return fmt.Errorf("cannot convert %T to %v", value, v.Type())
+		}
+		if v.OverflowFloat(f) {
+			return fmt.Errorf("value %v overflows %v", value, v.Type())
+		}
+		v.SetFloat(f)
+		return nil
+	default:
+	}
+	return fmt.Errorf("cannot convert %T to %v", value, v.Type())
+}
@@ -0,0 +1,185 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordsReader is a RecordReader over records in memory.
+type recordsReader struct {
+	records []arrow.RecordBatch
+	index   int
+}
+
+func (r *recordsReader) Next() bool {
+	r.index++
+	return r.index <= len(r.records)
+}
+
+func (r *recordsReader) RecordBatch() arrow.RecordBatch { //nolint:ireturn
+	return r.records[r.index-1]
+}
+
+func (r *recordsReader) Err() error { return nil }
+
+func (r *recordsReader) Schema() *arrow.Schema {
+	return r.records[0].Schema()
+}
+
+type cpuRow struct {
+	Measurement string    `lp:"measurement"`
+	Host        string    `lp:"tag,host"`
+	Usage       float64   `lp:"field,usage_user"`
+	Count       int32     `lp:"field,count"`
+	Total       *uint64   `lp:"field,total"`
+	Any         any       `lp:"field,any"`
+	Time        time.Time `lp:"timestamp"`
+	Ignored     string
+}
+
+func cpuSchema() *arrow.Schema {
+	return arrow.NewSchema([]arrow.Field{
+		{Name: "iox::measurement", Type: arrow.BinaryTypes.String},
+		{Name: "host", Type: arrow.BinaryTypes.String,
+			Metadata: arrow.NewMetadata([]string{"iox::column::type"}, []string{"iox::column_type::tag"})},
+		{Name: "usage_user", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "count", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "total", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "any", Type: arrow.BinaryTypes.String},
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{Name: "other", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+}
+
+func TestQueryAsDecode(t *testing.T) {
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, cpuSchema())
+	defer rb.Release()
+	rb.Field(0).(*array.StringBuilder).AppendValues([]string{"cpu", "cpu"}, nil)
+	rb.Field(1).(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	rb.Field(2).(*array.Int64Builder).AppendValues([]int64{10, 0}, []bool{true, false})
+	rb.Field(3).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	rb.Field(4).(*array.Int64Builder).AppendValues([]int64{100, 0}, []bool{true, false})
+	rb.Field(5).(*array.StringBuilder).AppendValues([]string{"x", "y"}, nil)
+	rb.Field(6).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{1000, 2000}, nil)
+	rb.Field(7).(*array.Float64Builder).AppendValues([]float64{1, 2}, nil)
+	rec := rb.NewRecord()
+	defer rec.Release()
+
+	it, err := NewTypedQueryIteratorFromReader[cpuRow](&recordsReader{records: []arrow.RecordBatch{rec}})
+	require.NoError(t, err)
+
+	row, err := it.Next()
+	require.NoError(t, err)
+	total := uint64(100)
+	assert.Equal(t, cpuRow{
+		Measurement: "cpu",
+		Host:        "a",
+		Usage:       10,
+		Count:       1,
+		Total:       &total,
+		Any:         "x",
+		Time:        time.UnixMilli(1000),
+	}, row)
+	assert.Equal(t, 0, it.Index())
+
+	row, err = it.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "b", row.Host)
+	assert.Zero(t, row.Usage)
+	assert.Nil(t, row.Total)
+	assert.Equal(t, time.UnixMilli(2000), row.Time)
+
+	_, err = it.Next()
+	assert.ErrorIs(t, err, Done)
+}
+
+func TestQueryAsDecodeErrors(t *testing.T) {
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, cpuSchema())
+	defer rb.Release()
+	rb.Field(0).(*array.StringBuilder).Append("cpu")
+	rb.Field(1).(*array.StringBuilder).Append("a")
+	rb.Field(2).(*array.Int64Builder).Append(1)
+	rb.Field(3).(*array.Int64Builder).Append(1 << 40)
+	rb.Field(4).(*array.Int64Builder).Append(-1)
+	rb.Field(5).(*array.StringBuilder).Append("x")
+	rb.Field(6).(*array.TimestampBuilder).Append(0)
+	rb.Field(7).(*array.Float64Builder).Append(0)
+	rec := rb.NewRecord()
+	defer rec.Release()
+
+	// int64 overflowing int32
+	it, err := NewTypedQueryIteratorFromReader[cpuRow](&recordsReader{records: []arrow.RecordBatch{rec}})
+	require.NoError(t, err)
+	_, err = it.Next()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decode column 'count' into field 'Count'")
+
+	// string into a numeric field
+	type wrongType struct {
+		Host int64 `lp:"tag,host"`
+	}
+	it2, err := NewTypedQueryIteratorFromReader[wrongType](&recordsReader{records: []arrow.RecordBatch{rec}})
+	require.NoError(t, err)
+	_, err = it2.Next()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot convert string to int64")
+}
+
+func TestQueryAsInvalidType(t *testing.T) {
+	reader := &recordsReader{}
+	_, err := NewTypedQueryIteratorFromReader[int](reader)
+	assert.Error(t, err)
+
+	type invalidTag struct {
+		V float64 `lp:"value"`
+	}
+	_, err = NewTypedQueryIteratorFromReader[invalidTag](reader)
+	assert.Error(t, err)
+
+	type invalidTimestamp struct {
+		T int64 `lp:"timestamp"`
+	}
+	_, err = NewTypedQueryIteratorFromReader[invalidTimestamp](reader)
+	assert.Error(t, err)
+
+	type unexported struct {
+		v float64 `lp:"field,v"`
+	}
+	_, err = NewTypedQueryIteratorFromReader[unexported](reader)
+	assert.Error(t, err)
+
+	c, err := New(ClientConfig{Host: "http://localhost:8086", Token: "my-token", Database: "db"})
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = QueryAs[int](context.Background(), c, "SELECT 1", nil)
+	assert.Error(t, err)
+}
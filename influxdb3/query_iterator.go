@@ -62,6 +62,8 @@ type QueryIterator struct {
 	current map[string]any
 	// Done
 	done bool
+	// View of the current record returned by NextBatch
+	batch BatchView
 }
 
 // NewQueryIterator creates a new QueryIterator instance with the provided flight.Reader.
@@ -103,22 +105,71 @@ func (i *QueryIterator) Next() bool {
 		i.indexInRecord = 0
 	}
 
-	readerSchema := i.reader.Schema()
-	obj := make(map[string]any, len(i.record.Columns()))
+	obj, err := rowToMap(i.reader.Schema(), i.record, i.indexInRecord)
+	if err != nil {
+		panic(err)
+	}
+
+	i.current = obj
+
+	return true
+}
+
+// NextBatch reads the next record batch of the flight reader and returns a typed view of it,
+// or nil if there are no more batches; check Err for the reason. Unlike Next, it does not
+// convert the rows to maps, the values are read from the Arrow arrays by the typed
+// accessors of BatchView. Use either Next or NextBatch to consume an iterator, not both.
+//
+//	for batch := iterator.NextBatch(); batch != nil; batch = iterator.NextBatch() {
+//		usage, err := batch.Float64(batch.ColumnIndex("usage"))
+//		if err != nil {
+//			return err
+//		}
+//		for row := range batch.NumRows() {
+//			if !usage.IsNull(row) {
+//				sum += usage.Value(row)
+//			}
+//		}
+//	}
+//	if err := iterator.Err(); err != nil {
+//		return err
+//	}
+//
+// Returns:
+//   - A view of the next record batch, valid until the next call of NextBatch or Close.
+func (i *QueryIterator) NextBatch() *BatchView {
+	if i.done {
+		return nil
+	}
+	if !i.reader.Next() {
+		if readError := i.reader.Err(); readError != nil && i.err == nil {
+			i.err = readError
+		}
+		i.done = true
+		return nil
+	}
+	i.record = i.reader.RecordBatch()
+	i.indexInRecord = int(i.record.NumRows()) - 1
+	i.i += i.record.NumRows()
+	i.current = nil
+	i.batch = BatchView{record: i.record}
+	return &i.batch
+}
 
-	for ci, col := range i.record.Columns() {
+func rowToMap(readerSchema *arrow.Schema, record arrow.RecordBatch, rowIndex int) (map[string]any, error) {
+	obj := make(map[string]any, len(record.Columns()))
+
+	for ci, col := range record.Columns() {
 		field := readerSchema.Field(ci)
 		name := field.Name
-		value, _, err := getArrowValue(col, field, i.indexInRecord)
+		value, _, err := getArrowValue(col, field, rowIndex)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		obj[name] = value
 	}
 
-	i.current = obj
-
-	return true
+	return obj, nil
 }
 
 // AsPoints return data from InfluxDB v3 into PointValues structure.
@@ -127,6 +178,14 @@ func (i *QueryIterator) AsPoints() *PointValues {
 }
 
 func rowToPointValue(record arrow.RecordBatch, rowIndex int) *PointValues {
+	p, err := rowToPointValueWithError(record, rowIndex)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func rowToPointValueWithError(record arrow.RecordBatch, rowIndex int) (*PointValues, error) {
 	readerSchema := record.Schema()
 	p := NewPointValues("")
 
@@ -135,7 +194,7 @@ func rowToPointValue(record arrow.RecordBatch, rowIndex int) *PointValues {
 		name := field.Name
 		value, columnType, err := getArrowValue(col, field, rowIndex)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		if value == nil {
 			continue
@@ -162,7 +221,7 @@ func rowToPointValue(record arrow.RecordBatch, rowIndex int) *PointValues {
 		}
 	}
 
-	return p
+	return p, nil
 }
 
 // Value returns the current value from the flight reader as a map object.
@@ -213,53 +272,90 @@ func (i *QueryIterator) Err() error { return i.err }
 // Returns:
 //   - The underlying flight.Reader.
 func (i *QueryIterator) Raw() *flight.Reader {
-	if r, ok := i.reader.(*cancelingRecordReader); ok {
+	reader := i.reader
+	if r, ok := reader.(*statsRecordReader); ok {
+		reader = r.reader
+	}
+	if r, ok := reader.(*restartingRecordReader); ok {
+		reader = r.reader
+	}
+	if r, ok := reader.(*cancelingRecordReader); ok {
 		return r.Reader()
-	} else if f, ok := i.reader.(*flight.Reader); ok {
+	} else if f, ok := reader.(*flight.Reader); ok {
 		return f
 	}
 	return nil
 }
 
+// Close cancels the query and releases the received data. Next returns false once the
+// iterator is closed. It is safe to call Close more than once, or after the result is read completely.
+//
+//	iterator, err := client.Query(ctx, query)
+//	if err != nil {
+//		return err
+//	}
+//	defer iterator.Close()
+//
+// Returns:
+//   - Always nil, the error of the query is returned by Err.
+func (i *QueryIterator) Close() error {
+	i.done = true
+	i.record = nil
+	i.batch = BatchView{}
+	i.current = nil
+	if i.reader != nil {
+		releaseReader(i.reader)
+	}
+	return nil
+}
+
+// Stats returns the statistics of the query, see QueryStats.
+// The statistics are empty when the iterator was not created by a Client query method.
+func (i *QueryIterator) Stats() QueryStats {
+	return readerStats(i.reader)
+}
+
+// arrowTypeExtractors read a value of an Arrow array by its type.
+var arrowTypeExtractors = map[arrow.Type]func(arrow.Array, int) any{
+	arrow.BOOL:                    func(arr arrow.Array, i int) any { return arr.(*array.Boolean).Value(i) },
+	arrow.UINT8:                   func(arr arrow.Array, i int) any { return arr.(*array.Uint8).Value(i) },
+	arrow.INT8:                    func(arr arrow.Array, i int) any { return arr.(*array.Int8).Value(i) },
+	arrow.UINT16:                  func(arr arrow.Array, i int) any { return arr.(*array.Uint16).Value(i) },
+	arrow.INT16:                   func(arr arrow.Array, i int) any { return arr.(*array.Int16).Value(i) },
+	arrow.UINT32:                  func(arr arrow.Array, i int) any { return arr.(*array.Uint32).Value(i) },
+	arrow.INT32:                   func(arr arrow.Array, i int) any { return arr.(*array.Int32).Value(i) },
+	arrow.UINT64:                  func(arr arrow.Array, i int) any { return arr.(*array.Uint64).Value(i) },
+	arrow.INT64:                   func(arr arrow.Array, i int) any { return arr.(*array.Int64).Value(i) },
+	arrow.FLOAT16:                 func(arr arrow.Array, i int) any { return arr.(*array.Float16).Value(i) },
+	arrow.FLOAT32:                 func(arr arrow.Array, i int) any { return arr.(*array.Float32).Value(i) },
+	arrow.FLOAT64:                 func(arr arrow.Array, i int) any { return arr.(*array.Float64).Value(i) },
+	arrow.STRING:                  func(arr arrow.Array, i int) any { return arr.(*array.String).Value(i) },
+	arrow.BINARY:                  func(arr arrow.Array, i int) any { return arr.(*array.Binary).Value(i) },
+	arrow.FIXED_SIZE_BINARY:       func(arr arrow.Array, i int) any { return arr.(*array.FixedSizeBinary).Value(i) },
+	arrow.DATE32:                  func(arr arrow.Array, i int) any { return arr.(*array.Date32).Value(i) },
+	arrow.DATE64:                  func(arr arrow.Array, i int) any { return arr.(*array.Date64).Value(i) },
+	arrow.TIMESTAMP:               func(arr arrow.Array, i int) any { return arr.(*array.Timestamp).Value(i) },
+	arrow.TIME32:                  func(arr arrow.Array, i int) any { return arr.(*array.Time32).Value(i) },
+	arrow.TIME64:                  func(arr arrow.Array, i int) any { return arr.(*array.Time64).Value(i) },
+	arrow.INTERVAL_MONTHS:         func(arr arrow.Array, i int) any { return arr.(*array.MonthInterval).Value(i) },
+	arrow.INTERVAL_DAY_TIME:       func(arr arrow.Array, i int) any { return arr.(*array.DayTimeInterval).Value(i) },
+	arrow.DECIMAL128:              func(arr arrow.Array, i int) any { return arr.(*array.Decimal128).Value(i) },
+	arrow.DECIMAL256:              func(arr arrow.Array, i int) any { return arr.(*array.Decimal256).Value(i) },
+	arrow.DURATION:                func(arr arrow.Array, i int) any { return arr.(*array.Duration).Value(i) },
+	arrow.LARGE_STRING:            func(arr arrow.Array, i int) any { return arr.(*array.LargeString).Value(i) },
+	arrow.LARGE_BINARY:            func(arr arrow.Array, i int) any { return arr.(*array.LargeBinary).Value(i) },
+	arrow.INTERVAL_MONTH_DAY_NANO: func(arr arrow.Array, i int) any { return arr.(*array.MonthDayNanoInterval).Value(i) },
+	arrow.NULL:                    func(arr arrow.Array, i int) any { return arr.(*array.Null).Value(i) },
+}
+
 func getArrowValue(arrayNoType arrow.Array, field arrow.Field, i int) (any, responseColumnType, error) {
 	var columnType = responseColumnTypeUnknown
 	if arrayNoType.IsNull(i) {
 		return nil, columnType, nil
 	}
-	typeExtractor := map[arrow.Type]func(arrow.Array, int) any{
-		arrow.BOOL:                    func(arr arrow.Array, i int) any { return arr.(*array.Boolean).Value(i) },
-		arrow.UINT8:                   func(arr arrow.Array, i int) any { return arr.(*array.Uint8).Value(i) },
-		arrow.INT8:                    func(arr arrow.Array, i int) any { return arr.(*array.Int8).Value(i) },
-		arrow.UINT16:                  func(arr arrow.Array, i int) any { return arr.(*array.Uint16).Value(i) },
-		arrow.INT16:                   func(arr arrow.Array, i int) any { return arr.(*array.Int16).Value(i) },
-		arrow.UINT32:                  func(arr arrow.Array, i int) any { return arr.(*array.Uint32).Value(i) },
-		arrow.INT32:                   func(arr arrow.Array, i int) any { return arr.(*array.Int32).Value(i) },
-		arrow.UINT64:                  func(arr arrow.Array, i int) any { return arr.(*array.Uint64).Value(i) },
-		arrow.INT64:                   func(arr arrow.Array, i int) any { return arr.(*array.Int64).Value(i) },
-		arrow.FLOAT16:                 func(arr arrow.Array, i int) any { return arr.(*array.Float16).Value(i) },
-		arrow.FLOAT32:                 func(arr arrow.Array, i int) any { return arr.(*array.Float32).Value(i) },
-		arrow.FLOAT64:                 func(arr arrow.Array, i int) any { return arr.(*array.Float64).Value(i) },
-		arrow.STRING:                  func(arr arrow.Array, i int) any { return arr.(*array.String).Value(i) },
-		arrow.BINARY:                  func(arr arrow.Array, i int) any { return arr.(*array.Binary).Value(i) },
-		arrow.FIXED_SIZE_BINARY:       func(arr arrow.Array, i int) any { return arr.(*array.FixedSizeBinary).Value(i) },
-		arrow.DATE32:                  func(arr arrow.Array, i int) any { return arr.(*array.Date32).Value(i) },
-		arrow.DATE64:                  func(arr arrow.Array, i int) any { return arr.(*array.Date64).Value(i) },
-		arrow.TIMESTAMP:               func(arr arrow.Array, i int) any { return arr.(*array.Timestamp).Value(i) },
-		arrow.TIME32:                  func(arr arrow.Array, i int) any { return arr.(*array.Time32).Value(i) },
-		arrow.TIME64:                  func(arr arrow.Array, i int) any { return arr.(*array.Time64).Value(i) },
-		arrow.INTERVAL_MONTHS:         func(arr arrow.Array, i int) any { return arr.(*array.MonthInterval).Value(i) },
-		arrow.INTERVAL_DAY_TIME:       func(arr arrow.Array, i int) any { return arr.(*array.DayTimeInterval).Value(i) },
-		arrow.DECIMAL128:              func(arr arrow.Array, i int) any { return arr.(*array.Decimal128).Value(i) },
-		arrow.DECIMAL256:              func(arr arrow.Array, i int) any { return arr.(*array.Decimal256).Value(i) },
-		arrow.DURATION:                func(arr arrow.Array, i int) any { return arr.(*array.Duration).Value(i) },
-		arrow.LARGE_STRING:            func(arr arrow.Array, i int) any { return arr.(*array.LargeString).Value(i) },
-		arrow.LARGE_BINARY:            func(arr arrow.Array, i int) any { return arr.(*array.LargeBinary).Value(i) },
-		arrow.INTERVAL_MONTH_DAY_NANO: func(arr arrow.Array, i int) any { return arr.(*array.MonthDayNanoInterval).Value(i) },
-		arrow.NULL:                    func(arr arrow.Array, i int) any { return arr.(*array.Null).Value(i) },
-	}
 
 	dataType := arrayNoType.DataType().ID()
-	if extractor, exists := typeExtractor[dataType]; exists {
+	if extractor, exists := arrowTypeExtractors[dataType]; exists {
 		value := extractor(arrayNoType, i)
 
 		if metadata, hasMetadata := field.Metadata.GetValue("iox::column::type"); hasMetadata {
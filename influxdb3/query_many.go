@@ -0,0 +1,237 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// defaultQueryManyConcurrency specifies the default value of QueryOptions.QueryConcurrency.
+const defaultQueryManyConcurrency = 4
+
+// QueryMany runs the same query against each of the databases concurrently and returns the rows
+// of all results, each row tagged with its source database. At most QueryOptions.QueryConcurrency
+// databases are queried at the same time, see WithQueryConcurrency.
+//
+// A failure of one database does not stop the others; the errors are collected per database
+// and returned by MultiQueryIterator.Errors once the iteration is complete. Rows of the databases
+// are interleaved, rows of a single database are returned in the order of its result.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the requests.
+//   - query: The query string to execute.
+//   - databases: The databases to query.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A result iterator (*MultiQueryIterator). It should be closed when not read to the end.
+//   - An error, if any.
+func (c *Client) QueryMany(ctx context.Context, query string, databases []string,
+	options ...QueryOption) (*MultiQueryIterator, error) {
+	if len(databases) == 0 {
+		return nil, errors.New("no databases to query")
+	}
+	opts := newQueryOptions(&DefaultQueryOptions, options)
+	concurrency := opts.QueryConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultQueryManyConcurrency
+	}
+	concurrency = min(concurrency, len(databases))
+
+	ctx, cancel := context.WithCancel(ctx)
+	it := &MultiQueryIterator{
+		cancel:  cancel,
+		batches: make(chan databaseBatch, concurrency),
+		errors:  make(map[string]error),
+		row:     -1,
+	}
+
+	jobs := make(chan string)
+	go func() {
+		defer close(jobs)
+		for _, database := range databases {
+			select {
+			case jobs <- database:
+			case <-ctx.Done():
+				it.setError(database, ctx.Err())
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Go(func() {
+			for database := range jobs {
+				dbOpts := *opts
+				dbOpts.Database = database
+				c.queryDatabaseBatches(ctx, query, &dbOpts, it)
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(it.batches)
+	}()
+
+	return it, nil
+}
+
+// queryDatabaseBatches sends the record batches of a single database to it.
+func (c *Client) queryDatabaseBatches(ctx context.Context, query string, options *QueryOptions, it *MultiQueryIterator) {
+	reader, err := c.getReader(ctx, query, nil, options)
+	if err != nil {
+		it.setError(options.Database, err)
+		return
+	}
+	defer releaseReader(reader)
+
+	for reader.Next() {
+		record := reader.RecordBatch()
+		record.Retain()
+		select {
+		case it.batches <- databaseBatch{database: options.Database, record: record}:
+		case <-ctx.Done():
+			record.Release()
+			it.setError(options.Database, ctx.Err())
+			return
+		}
+	}
+	if err := reader.Err(); err != nil {
+		it.setError(options.Database, err)
+	}
+}
+
+// databaseBatch is a record batch received from a database.
+type databaseBatch struct {
+	database string
+	record   arrow.RecordBatch
+}
+
+// MultiQueryIterator iterates over the rows returned by Client.QueryMany.
+type MultiQueryIterator struct {
+	cancel  context.CancelFunc
+	batches chan databaseBatch
+	// Errors of the databases
+	mu     sync.Mutex
+	errors map[string]error
+	// Current record and its database
+	record   arrow.RecordBatch
+	database string
+	// Index of row of current object in current record
+	row int
+	// Current object
+	current map[string]any
+	done    bool
+}
+
+// Next reads the next row and returns true if a row is present.
+// Rows which cannot be converted are skipped and reported by Errors.
+//
+// Returns:
+//   - true if a row is present, false otherwise.
+func (it *MultiQueryIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	for {
+		it.row++
+		for it.record == nil || it.row >= int(it.record.NumRows()) {
+			it.releaseRecord()
+			batch, ok := <-it.batches
+			if !ok {
+				it.done = true
+				it.cancel()
+				return false
+			}
+			it.record, it.database, it.row = batch.record, batch.database, 0
+		}
+
+		value, err := rowToMap(it.record.Schema(), it.record, it.row)
+		if err != nil {
+			it.setError(it.database, err)
+			continue
+		}
+		it.current = value
+		return true
+	}
+}
+
+// Value returns the current row as a map object, see QueryIterator.Value.
+func (it *MultiQueryIterator) Value() map[string]any {
+	return it.current
+}
+
+// Database returns the database the current row was returned by.
+func (it *MultiQueryIterator) Database() string {
+	return it.database
+}
+
+// Done returns true if there are no more rows.
+func (it *MultiQueryIterator) Done() bool {
+	return it.done
+}
+
+// Errors returns the errors of the databases that failed, keyed by database.
+// It is complete after Next returned false.
+func (it *MultiQueryIterator) Errors() map[string]error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return maps.Clone(it.errors)
+}
+
+// Close cancels the queries and releases the received data. Next returns false once the
+// iterator is closed. It is safe to call Close more than once.
+//
+// Returns:
+//   - Always nil, the errors of the queries are returned by Errors.
+func (it *MultiQueryIterator) Close() error {
+	it.done = true
+	it.current = nil
+	it.cancel()
+	it.releaseRecord()
+	for batch := range it.batches {
+		batch.record.Release()
+	}
+	return nil
+}
+
+func (it *MultiQueryIterator) releaseRecord() {
+	if it.record != nil {
+		it.record.Release()
+		it.record = nil
+	}
+}
+
+// setError records the first error of a database.
+func (it *MultiQueryIterator) setError(database string, err error) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if _, exists := it.errors[database]; !exists {
+		it.errors[database] = err
+	}
+}
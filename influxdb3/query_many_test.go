@@ -0,0 +1,119 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// databaseFlightServer returns a row with the name of the queried database,
+// queries of the "broken" database fail.
+type databaseFlightServer struct {
+	flight.BaseFlightServer
+}
+
+func (f *databaseFlightServer) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
+	var ticket struct {
+		Database string `json:"database"`
+	}
+	if err := json.Unmarshal(tkt.GetTicket(), &ticket); err != nil {
+		return err
+	}
+	if ticket.Database == "broken" {
+		return status.Error(codes.NotFound, "database not found")
+	}
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{ticket.Database, ticket.Database}, nil)
+	rec := builder.NewRecord()
+	defer rec.Release()
+
+	w := flight.NewRecordWriter(fs, ipc.WithSchema(schema))
+	return w.Write(rec)
+}
+
+// startDatabaseFlightServer returns a client without a default database.
+func startDatabaseFlightServer(t *testing.T) *Client {
+	t.Helper()
+	return startFlightServer(t, &databaseFlightServer{}, func(config *ClientConfig) {
+		config.Database = ""
+	})
+}
+
+func TestQueryMany(t *testing.T) {
+	client := startDatabaseFlightServer(t)
+
+	databases := []string{"tenant-a", "tenant-b", "broken", "tenant-c"}
+	it, err := client.QueryMany(context.Background(), "SELECT name FROM t", databases, WithQueryConcurrency(2))
+	require.NoError(t, err)
+	defer it.Close()
+
+	var rows []string
+	for it.Next() {
+		assert.Equal(t, it.Database(), it.Value()["name"])
+		rows = append(rows, it.Database())
+	}
+	sort.Strings(rows)
+	assert.Equal(t, []string{"tenant-a", "tenant-a", "tenant-b", "tenant-b", "tenant-c", "tenant-c"}, rows)
+	assert.True(t, it.Done())
+
+	errs := it.Errors()
+	require.Len(t, errs, 1)
+	require.Error(t, errs["broken"])
+	assert.Equal(t, codes.NotFound, status.Code(errs["broken"]))
+}
+
+func TestQueryManyClose(t *testing.T) {
+	client := startDatabaseFlightServer(t)
+
+	it, err := client.QueryMany(context.Background(), "SELECT name FROM t", []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	require.NoError(t, it.Close())
+	assert.False(t, it.Next())
+	require.NoError(t, it.Close())
+}
+
+func TestQueryManyNoDatabases(t *testing.T) {
+	client := startDatabaseFlightServer(t)
+
+	_, err := client.QueryMany(context.Background(), "SELECT 1", nil)
+	require.EqualError(t, err, "no databases to query")
+}
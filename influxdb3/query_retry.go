@@ -0,0 +1,189 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QueryRetryOptions holds the retry policy applied to queries failing with transient gRPC errors,
+// e.g. during rolling restarts of queriers. The backoff between attempts is configured by the embedded
+// RetryOptions, RetryOptions.RetryableStatusCodes is not used. The zero value disables retrying.
+type QueryRetryOptions struct {
+	RetryOptions
+
+	// RetryableCodes lists the gRPC status codes that cause a query to be retried.
+	// Default value: Unavailable, ResourceExhausted.
+	RetryableCodes []codes.Code
+
+	// RestartQuery repeats the query also when the result stream fails before the first record batch
+	// is returned. Enable it for idempotent queries only.
+	// By default, only the failed initial request of a query is retried.
+	// QueryTimeRange ignores it, a failed window is always repeated as a whole.
+	RestartQuery bool
+}
+
+// defaultRetryableQueryCodes specifies the default value of QueryRetryOptions.RetryableCodes.
+var defaultRetryableQueryCodes = []codes.Code{
+	codes.Unavailable,
+	codes.ResourceExhausted,
+}
+
+// isRetryableCode reports whether the query failure err may succeed when repeated.
+func (r *QueryRetryOptions) isRetryableCode(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	retryable := r.RetryableCodes
+	if retryable == nil {
+		retryable = defaultRetryableQueryCodes
+	}
+	return slices.Contains(retryable, s.Code())
+}
+
+// queryRetryOptions returns the retry policy of a query, nil when retrying is disabled.
+func (c *Client) queryRetryOptions(options *QueryOptions) *QueryRetryOptions {
+	retry := c.config.QueryRetry
+	if options.QueryRetry != nil {
+		retry = options.QueryRetry
+	}
+	if retry == nil || !retry.enabled() {
+		return nil
+	}
+	return retry
+}
+
+// queryRetry tracks the attempts of a single query.
+type queryRetry struct {
+	options *QueryRetryOptions
+	attempt int
+	start   time.Time
+}
+
+// backoff waits before the next attempt and reports whether the query failing with err is to be repeated.
+func (r *queryRetry) backoff(ctx context.Context, err error) bool {
+	if r.attempt >= r.options.MaxAttempts || !r.options.isRetryableCode(ctx, err) {
+		return false
+	}
+	d := r.options.delay(r.attempt, err)
+	if r.options.MaxElapsedTime > 0 && time.Since(r.start)+d > r.options.MaxElapsedTime {
+		return false
+	}
+
+	timer := time.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return false
+	case <-timer.C:
+	}
+	r.attempt++
+	return true
+}
+
+// openWithRetry opens a query result by open, repeating it according to retry.
+func openWithRetry(ctx context.Context, retry *QueryRetryOptions,
+	open func() (RecordReader, error)) (RecordReader, error) { //nolint:ireturn
+	r := &queryRetry{options: retry, attempt: 1, start: time.Now()}
+	reader, err := open()
+	for err != nil {
+		if !r.backoff(ctx, err) {
+			return nil, err
+		}
+		reader, err = open()
+	}
+	if !retry.RestartQuery {
+		return reader, nil
+	}
+	return &restartingRecordReader{ctx: ctx, reader: reader, open: open, retry: r}, nil
+}
+
+// restartingRecordReader repeats a query whose result stream fails before the first record batch is returned.
+type restartingRecordReader struct {
+	ctx    context.Context
+	reader RecordReader
+	open   func() (RecordReader, error)
+	retry  *queryRetry
+	// a record batch was returned, the query cannot be repeated anymore
+	started bool
+	err     error
+}
+
+// Next moves to the next record and returns true if a record is present.
+func (r *restartingRecordReader) Next() bool {
+	for r.err == nil {
+		if r.reader.Next() {
+			r.started = true
+			return true
+		}
+		err := r.reader.Err()
+		if err == nil || r.started || !r.retry.backoff(r.ctx, err) {
+			return false
+		}
+		releaseReader(r.reader)
+		reader, err := r.open()
+		for err != nil && r.retry.backoff(r.ctx, err) {
+			reader, err = r.open()
+		}
+		if err != nil {
+			r.err = err
+			return false
+		}
+		r.reader = reader
+	}
+	return false
+}
+
+// RecordBatch returns the current record.
+func (r *restartingRecordReader) RecordBatch() arrow.RecordBatch { //nolint:ireturn
+	return r.reader.RecordBatch()
+}
+
+// Err returns the error of the last attempt.
+func (r *restartingRecordReader) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.reader.Err()
+}
+
+// Schema returns the schema of the current attempt.
+func (r *restartingRecordReader) Schema() *arrow.Schema {
+	return r.reader.Schema()
+}
+
+// Release releases the reader of the current attempt.
+func (r *restartingRecordReader) Release() {
+	releaseReader(r.reader)
+}
@@ -0,0 +1,221 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyFlightServer fails the first failures DoGet calls with code.
+type flakyFlightServer struct {
+	flightServer
+	mu       sync.Mutex
+	calls    int
+	failures int
+	code     codes.Code
+}
+
+func (f *flakyFlightServer) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
+	f.mu.Lock()
+	f.calls++
+	fail := f.calls <= f.failures
+	f.mu.Unlock()
+	if fail {
+		return status.Error(f.code, "querier restarting")
+	}
+	return f.flightServer.DoGet(tkt, fs)
+}
+
+func (f *flakyFlightServer) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+var fastQueryRetry = QueryRetryOptions{
+	RetryOptions: RetryOptions{MaxAttempts: 3, InitialInterval: time.Millisecond},
+}
+
+func countRows(t *testing.T, it *QueryIterator) int {
+	t.Helper()
+	rows := 0
+	for it.Next() {
+		rows++
+	}
+	require.NoError(t, it.Err())
+	return rows
+}
+
+func TestQueryRetryDoGet(t *testing.T) {
+	server := &flakyFlightServer{failures: 2, code: codes.Unavailable}
+	client := startFlightServer(t, server, func(config *ClientConfig) {
+		config.QueryRetry = &fastQueryRetry
+	})
+
+	it, err := client.Query(context.Background(), "SELECT * FROM data")
+	require.NoError(t, err)
+	defer it.Close()
+	assert.Equal(t, 5, countRows(t, it))
+	assert.Equal(t, 3, server.callCount())
+}
+
+func TestQueryRetryAttemptsExhausted(t *testing.T) {
+	server := &flakyFlightServer{failures: 3, code: codes.ResourceExhausted}
+	client := startFlightServer(t, server, func(config *ClientConfig) {
+		config.QueryRetry = &fastQueryRetry
+	})
+
+	_, err := client.Query(context.Background(), "SELECT * FROM data")
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, 3, server.callCount())
+}
+
+func TestQueryRetryNotRetryableCode(t *testing.T) {
+	server := &flakyFlightServer{failures: 1, code: codes.InvalidArgument}
+	client := startFlightServer(t, server, func(config *ClientConfig) {
+		config.QueryRetry = &fastQueryRetry
+	})
+
+	_, err := client.Query(context.Background(), "SELECT * FROM data")
+	require.Error(t, err)
+	assert.Equal(t, 1, server.callCount())
+}
+
+func TestQueryRetryOption(t *testing.T) {
+	server := &flakyFlightServer{failures: 1, code: codes.Unavailable}
+	client := startFlightServer(t, server, nil)
+
+	_, err := client.Query(context.Background(), "SELECT * FROM data")
+	require.Error(t, err)
+
+	it, err := client.Query(context.Background(), "SELECT * FROM data", WithQueryRetry(fastQueryRetry))
+	require.NoError(t, err)
+	defer it.Close()
+	assert.Equal(t, 5, countRows(t, it))
+	assert.Equal(t, 2, server.callCount())
+}
+
+// failingReader is a RecordReader failing before the first record.
+type failingReader struct {
+	err      error
+	released bool
+}
+
+func (r *failingReader) Next() bool                     { return false }
+func (r *failingReader) RecordBatch() arrow.RecordBatch { return nil } //nolint:ireturn
+func (r *failingReader) Err() error                     { return r.err }
+func (r *failingReader) Schema() *arrow.Schema          { return nil }
+func (r *failingReader) Release()                       { r.released = true }
+
+func retryTestRecord() arrow.RecordBatch {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "v", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+	rb.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	return rb.NewRecord()
+}
+
+func TestQueryRetryRestartQuery(t *testing.T) {
+	rec := retryTestRecord()
+	defer rec.Release()
+
+	failed := &failingReader{err: status.Error(codes.Unavailable, "stream broken")}
+	readers := []RecordReader{failed, &recordsReader{records: []arrow.RecordBatch{rec}}}
+	opens := 0
+	open := func() (RecordReader, error) {
+		opens++
+		return readers[opens-1], nil
+	}
+
+	retry := fastQueryRetry
+	retry.RestartQuery = true
+	reader, err := openWithRetry(context.Background(), &retry, open)
+	require.NoError(t, err)
+
+	it := NewQueryIteratorFromReader(reader)
+	assert.Equal(t, 2, countRows(t, it))
+	assert.Equal(t, 2, opens)
+	assert.True(t, failed.released)
+}
+
+func TestQueryRetryNoRestartAfterFirstBatch(t *testing.T) {
+	rec := retryTestRecord()
+	defer rec.Release()
+
+	broken := status.Error(codes.Unavailable, "stream broken")
+	opens := 0
+	open := func() (RecordReader, error) {
+		opens++
+		return &brokenAfterReader{recordsReader: recordsReader{records: []arrow.RecordBatch{rec}}, err: broken}, nil
+	}
+
+	retry := fastQueryRetry
+	retry.RestartQuery = true
+	reader, err := openWithRetry(context.Background(), &retry, open)
+	require.NoError(t, err)
+
+	require.True(t, reader.Next())
+	require.False(t, reader.Next())
+	require.True(t, errors.Is(reader.Err(), broken))
+	assert.Equal(t, 1, opens)
+}
+
+// brokenAfterReader fails after its records are read.
+type brokenAfterReader struct {
+	recordsReader
+	err error
+}
+
+func (r *brokenAfterReader) Err() error {
+	if r.index > len(r.records) {
+		return r.err
+	}
+	return nil
+}
+
+func TestQueryRetryIsRetryableCode(t *testing.T) {
+	ctx := context.Background()
+	retry := &QueryRetryOptions{}
+	assert.True(t, retry.isRetryableCode(ctx, status.Error(codes.Unavailable, "")))
+	assert.False(t, retry.isRetryableCode(ctx, status.Error(codes.Aborted, "")))
+	assert.False(t, retry.isRetryableCode(ctx, assert.AnError))
+	assert.False(t, retry.isRetryableCode(ctx, context.Canceled))
+
+	retry.RetryableCodes = []codes.Code{codes.Aborted}
+	assert.True(t, retry.isRetryableCode(ctx, status.Error(codes.Aborted, "")))
+	assert.False(t, retry.isRetryableCode(ctx, status.Error(codes.Unavailable, "")))
+}
@@ -0,0 +1,147 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"google.golang.org/grpc/metadata"
+)
+
+// QueryStats holds statistics of a query execution collected while its result is read.
+type QueryStats struct {
+	// TimeToFirstBatch is the time from sending the query to receiving the first record batch.
+	TimeToFirstBatch time.Duration
+
+	// Duration is the time from sending the query to the end of its result,
+	// or to the last received record batch while the result is being read.
+	Duration time.Duration
+
+	// Batches is the number of received record batches.
+	Batches int64
+
+	// Rows is the number of received rows.
+	Rows int64
+
+	// Bytes is the approximate size of the received record batches, computed from their Arrow buffers.
+	Bytes int64
+
+	// Header holds the gRPC response headers, available when the result is read completely.
+	Header metadata.MD
+
+	// Trailer holds the gRPC response trailers, available when the result is read completely.
+	Trailer metadata.MD
+
+	// Done reports whether the result was read completely.
+	Done bool
+}
+
+// statsRecordReader is a RecordReader collecting QueryStats of the wrapped reader.
+type statsRecordReader struct {
+	reader RecordReader
+	start  time.Time
+	stats  QueryStats
+	// filled by gRPC call options when the stream ends
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func newStatsRecordReader() *statsRecordReader {
+	return &statsRecordReader{start: time.Now()}
+}
+
+func (sr *statsRecordReader) Next() bool {
+	if sr.stats.Done {
+		return false
+	}
+	if !sr.reader.Next() {
+		sr.stats.Done = true
+		sr.stats.Duration = time.Since(sr.start)
+		sr.stats.Header = sr.header
+		sr.stats.Trailer = sr.trailer
+		return false
+	}
+	record := sr.reader.RecordBatch()
+	sr.stats.Duration = time.Since(sr.start)
+	if sr.stats.Batches == 0 {
+		sr.stats.TimeToFirstBatch = sr.stats.Duration
+	}
+	sr.stats.Batches++
+	sr.stats.Rows += record.NumRows()
+	sr.stats.Bytes += recordSize(record)
+	return true
+}
+
+func (sr *statsRecordReader) RecordBatch() arrow.RecordBatch { //nolint:ireturn
+	return sr.reader.RecordBatch()
+}
+
+func (sr *statsRecordReader) Err() error {
+	return sr.reader.Err()
+}
+
+func (sr *statsRecordReader) Schema() *arrow.Schema {
+	return sr.reader.Schema()
+}
+
+// Release releases the wrapped reader.
+func (sr *statsRecordReader) Release() {
+	releaseReader(sr.reader)
+}
+
+// readerStats returns the statistics collected by reader, if any.
+func readerStats(reader RecordReader) QueryStats {
+	if sr, ok := reader.(*statsRecordReader); ok {
+		return sr.stats
+	}
+	return QueryStats{}
+}
+
+// recordSize returns the total length of the buffers of record.
+func recordSize(record arrow.RecordBatch) int64 {
+	var size int64
+	for _, col := range record.Columns() {
+		size += arrayDataSize(col.Data())
+	}
+	return size
+}
+
+func arrayDataSize(data arrow.ArrayData) int64 {
+	if data == nil {
+		return 0
+	}
+	var size int64
+	for _, buf := range data.Buffers() {
+		if buf != nil {
+			size += int64(buf.Len())
+		}
+	}
+	for _, child := range data.Children() {
+		size += arrayDataSize(child)
+	}
+	if data.DataType().ID() == arrow.DICTIONARY {
+		size += arrayDataSize(data.Dictionary())
+	}
+	return size
+}
@@ -0,0 +1,99 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// fake Flight server sending a query ID in the response header and trailer
+type statsFlightServer struct {
+	flightServer
+}
+
+func (f *statsFlightServer) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
+	if err := fs.SetHeader(metadata.Pairs("x-query-id", "q1")); err != nil {
+		return err
+	}
+	fs.SetTrailer(metadata.Pairs("x-query-rows", "5"))
+	return f.flightServer.DoGet(tkt, fs)
+}
+
+func TestQueryIteratorStats(t *testing.T) {
+	client := startFlightServer(t, &statsFlightServer{}, nil)
+
+	it, err := client.Query(context.Background(), "SELECT * FROM data")
+	require.NoError(t, err)
+	assert.Zero(t, it.Stats().Batches)
+	assert.NotNil(t, it.Raw())
+	for it.Next() {
+		stats := it.Stats()
+		assert.Equal(t, int64(1), stats.Batches)
+		assert.False(t, stats.Done)
+	}
+	require.NoError(t, it.Err())
+
+	stats := it.Stats()
+	assert.True(t, stats.Done)
+	assert.Equal(t, int64(1), stats.Batches)
+	assert.Equal(t, int64(5), stats.Rows)
+	assert.Positive(t, stats.Bytes)
+	assert.Positive(t, stats.TimeToFirstBatch)
+	assert.GreaterOrEqual(t, stats.Duration, stats.TimeToFirstBatch)
+	assert.Equal(t, []string{"q1"}, stats.Header.Get("x-query-id"))
+	assert.Equal(t, []string{"5"}, stats.Trailer.Get("x-query-rows"))
+
+	pit, err := client.QueryPointValue(context.Background(), "SELECT * FROM data")
+	require.NoError(t, err)
+	_, err = pit.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), pit.Stats().Rows)
+}
+
+func TestQueryStatsNotCollected(t *testing.T) {
+	assert.Equal(t, QueryStats{}, NewQueryIteratorFromReader(nil).Stats())
+}
+
+func TestRecordSize(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "s", Type: arrow.BinaryTypes.String},
+	}, nil)
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+	rb.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	rb.Field(1).(*array.StringBuilder).AppendValues([]string{"ab", "cd"}, nil)
+	rec := rb.NewRecord()
+	defer rec.Release()
+	// at least the int64 values, string offsets and string data
+	assert.GreaterOrEqual(t, recordSize(rec), int64(16+12+4))
+}
@@ -355,6 +355,93 @@ func TestQueryWithQueryTimeoutOK(t *testing.T) {
 	assert.Equal(t, int64(14), iSum)
 }
 
+// startFlightServer serves f and returns a client connected to it,
+// configure modifies the client configuration when not nil.
+func startFlightServer(t *testing.T, f flight.FlightServer, configure func(*ClientConfig)) *Client {
+	t.Helper()
+	config := ClientConfig{
+		Host:     "http://" + testutil.StartFlightServer(t, f),
+		Token:    "my_secret_token",
+		Database: "explore",
+	}
+	if configure != nil {
+		configure(&config)
+	}
+	client, err := New(config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+func TestQueryRows(t *testing.T) {
+	client := startFlightServer(t, &flightServer{}, nil)
+
+	var buff strings.Builder
+	iSum := int64(0)
+	for row, err := range client.QueryRows(context.Background(), "SELECT intField,stringField,floatField FROM data") {
+		require.NoError(t, err)
+		buff.WriteString(row["stringField"].(string))
+		iSum += row["intField"].(int64)
+	}
+	assert.Equal(t, "abcde", buff.String())
+	assert.Equal(t, int64(15), iSum)
+}
+
+func TestQueryRowsBreak(t *testing.T) {
+	client := startFlightServer(t, &flightServer{}, nil)
+
+	count := 0
+	for row, err := range client.QueryRowsWithParameters(context.Background(), "SELECT * FROM data WHERE x = $x",
+		QueryParameters{"x": 1}) {
+		require.NoError(t, err)
+		assert.Equal(t, "a", row["stringField"])
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestQueryPoints(t *testing.T) {
+	client := startFlightServer(t, &flightServer{}, nil)
+
+	fSum := 0.0
+	count := 0
+	for point, err := range client.QueryPoints(context.Background(), "SELECT * FROM data") {
+		require.NoError(t, err)
+		if f := point.GetDoubleField("floatField"); f != nil {
+			fSum += *f
+		}
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+	assert.Equal(t, float64(4), fSum)
+}
+
+func TestQueryRowsError(t *testing.T) {
+	c, err := New(ClientConfig{
+		Host:  "http://localhost:8086",
+		Token: "my-token",
+	})
+	require.NoError(t, err)
+	count := 0
+	for row, err := range c.QueryRows(context.Background(), "SHOW NAMESPACES") {
+		assert.Nil(t, row)
+		assert.EqualError(t, err, "database not specified")
+		count++
+	}
+	assert.Equal(t, 1, count)
+
+	for point, err := range c.QueryPointsWithParameters(context.Background(), "SHOW NAMESPACES", nil) {
+		assert.Nil(t, point)
+		assert.EqualError(t, err, "database not specified")
+	}
+}
+
 // fake Flight server implementation
 type flightServer struct {
 	flight.BaseFlightServer
@@ -401,3 +488,57 @@ func (c *callHeadersMiddleware) CallCompleted(ctx context.Context, err error) {
 
 func (c *callHeadersMiddleware) HeadersReceived(ctx context.Context, md metadata.MD) {
 }
+
+func TestQueryIteratorClose(t *testing.T) {
+	client := startFlightServer(t, &flightServer{}, nil)
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	client.allocator = mem
+
+	it, err := client.Query(context.Background(), "SELECT * FROM data")
+	require.NoError(t, err)
+	defer it.Close()
+	require.True(t, it.Next())
+	require.NoError(t, it.Close())
+	assert.False(t, it.Next())
+	assert.True(t, it.Done())
+	require.NoError(t, it.Close())
+	assert.NoError(t, it.Err())
+
+	// closing a drained iterator
+	it, err = client.Query(context.Background(), "SELECT * FROM data")
+	require.NoError(t, err)
+	for it.Next() {
+		_ = it.Value()
+	}
+	require.NoError(t, it.Close())
+}
+
+func TestPointValueIteratorClose(t *testing.T) {
+	client := startFlightServer(t, &flightServer{}, nil)
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	client.allocator = mem
+
+	it, err := client.QueryPointValue(context.Background(), "SELECT * FROM data")
+	require.NoError(t, err)
+	pv, err := it.Next()
+	require.NoError(t, err)
+	assert.NotNil(t, pv)
+	require.NoError(t, it.Close())
+	_, err = it.Next()
+	assert.ErrorIs(t, err, Done)
+	require.NoError(t, it.Close())
+}
+
+func TestQueryRowsBreakReleases(t *testing.T) {
+	client := startFlightServer(t, &flightServer{}, nil)
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	client.allocator = mem
+
+	for _, err := range client.QueryRows(context.Background(), "SELECT * FROM data") {
+		require.NoError(t, err)
+		break
+	}
+}
@@ -0,0 +1,288 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// TimeRangeOptions configures splitting of a query by QueryTimeRange.
+type TimeRangeOptions struct {
+	// Start of the time range, inclusive.
+	Start time.Time
+
+	// End of the time range, exclusive.
+	End time.Time
+
+	// Chunks is the number of windows of equal duration the range is split into.
+	// Default value: 1.
+	Chunks int
+
+	// Concurrency is the maximum number of windows queried, or buffered, at the same time.
+	// Default value: 4.
+	Concurrency int
+
+	// Retry is the retry policy of a single window, see QueryRetryOptions. A window is read completely
+	// before it is returned, so it is repeated also when its result stream fails and RestartQuery is ignored.
+	// Default value: the QueryRetry of the query options or of the client configuration.
+	Retry QueryRetryOptions
+}
+
+// defaultTimeRangeConcurrency specifies the default value of TimeRangeOptions.Concurrency.
+const defaultTimeRangeConcurrency = 4
+
+// QueryTimeRange splits the time range of a parameterized query into windows, queries the windows
+// concurrently and returns the results as a single RecordReader in the order of the windows.
+// The query must restrict time by the $start (inclusive) and $end (exclusive) parameters,
+// which are set to RFC3339 timestamps of each window, e.g.
+//
+//	SELECT * FROM cpu WHERE time >= $start AND time < $end ORDER BY time
+//
+// The result is ordered by time when the query orders each window by time.
+// Each window is read completely before it is returned, so that it can be retried
+// according to TimeRangeOptions.Retry; at most TimeRangeOptions.Concurrency windows are kept in memory.
+// The first window is received before QueryTimeRange returns. The returned reader should be released
+// by calling its Release method when not read to the end.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the requests.
+//   - query: The query string to execute.
+//   - parameters: Other query parameters, can be nil.
+//   - rangeOptions: The time range and how to split it.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - A reader of the merged result (*TimeRangeReader).
+//   - An error, if any.
+func (c *Client) QueryTimeRange(ctx context.Context, query string, parameters QueryParameters,
+	rangeOptions TimeRangeOptions, options ...QueryOption) (*TimeRangeReader, error) {
+	if !rangeOptions.End.After(rangeOptions.Start) {
+		return nil, errors.New("time range end must be after start")
+	}
+	chunks := max(rangeOptions.Chunks, 1)
+	concurrency := rangeOptions.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultTimeRangeConcurrency
+	}
+	opts := newQueryOptions(&DefaultQueryOptions, options)
+	retry := &rangeOptions.Retry
+	if !retry.enabled() {
+		retry = c.queryRetryOptions(opts)
+	}
+	// windows are retried as a whole, the single attempts are not repeated
+	opts.QueryRetry = &QueryRetryOptions{}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &TimeRangeReader{
+		cancel:  cancel,
+		results: make([]chan timeRangeChunk, chunks),
+		slots:   make(chan struct{}, concurrency),
+		index:   -1,
+	}
+	step := rangeOptions.End.Sub(rangeOptions.Start) / time.Duration(chunks)
+	for i := range chunks {
+		r.results[i] = make(chan timeRangeChunk, 1)
+	}
+	go func() {
+		for i := range chunks {
+			start := rangeOptions.Start.Add(time.Duration(i) * step)
+			end := start.Add(step)
+			if i == chunks-1 {
+				end = rangeOptions.End
+			}
+			params := maps.Clone(parameters)
+			if params == nil {
+				params = make(QueryParameters, 2)
+			}
+			params["start"] = start.UTC().Format(time.RFC3339Nano)
+			params["end"] = end.UTC().Format(time.RFC3339Nano)
+
+			// a slot is released when the window is consumed
+			select {
+			case r.slots <- struct{}{}:
+			case <-ctx.Done():
+				r.results[i] <- timeRangeChunk{err: ctx.Err()}
+				continue
+			}
+			go func() {
+				r.results[i] <- c.queryChunk(ctx, query, params, opts, retry)
+			}()
+		}
+	}()
+
+	// wait for the first window to know the schema
+	if !r.nextChunk() {
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// timeRangeChunk is the complete result of a single window.
+type timeRangeChunk struct {
+	schema  *arrow.Schema
+	records []arrow.RecordBatch
+	err     error
+}
+
+// queryChunk reads the complete result of a query, repeating it according to retry, which can be nil.
+func (c *Client) queryChunk(ctx context.Context, query string, parameters QueryParameters, options *QueryOptions,
+	retry *QueryRetryOptions) timeRangeChunk {
+	chunk := c.readChunk(ctx, query, parameters, options)
+	if retry == nil {
+		return chunk
+	}
+	r := &queryRetry{options: retry, attempt: 1, start: time.Now()}
+	for chunk.err != nil && r.backoff(ctx, chunk.err) {
+		chunk = c.readChunk(ctx, query, parameters, options)
+	}
+	return chunk
+}
+
+func (c *Client) readChunk(ctx context.Context, query string, parameters QueryParameters, options *QueryOptions) timeRangeChunk {
+	reader, err := c.getReader(ctx, query, parameters, options)
+	if err != nil {
+		return timeRangeChunk{err: err}
+	}
+	defer releaseReader(reader)
+
+	var chunk timeRangeChunk
+	for reader.Next() {
+		record := reader.RecordBatch()
+		record.Retain()
+		chunk.records = append(chunk.records, record)
+	}
+	chunk.schema = reader.Schema()
+	if err := reader.Err(); err != nil {
+		releaseRecords(chunk.records)
+		return timeRangeChunk{err: err}
+	}
+	return chunk
+}
+
+func releaseRecords(records []arrow.RecordBatch) {
+	for _, r := range records {
+		r.Release()
+	}
+}
+
+// TimeRangeReader is a RecordReader returning the results of the windows of QueryTimeRange in order.
+type TimeRangeReader struct {
+	cancel  context.CancelFunc
+	results []chan timeRangeChunk
+	// a slot is taken by each window being queried or buffered
+	slots chan struct{}
+	// number of windows received from results
+	received int
+	// current window, held is true while it takes a slot
+	chunk timeRangeChunk
+	held  bool
+	// index of the current record in the current window
+	index  int
+	schema *arrow.Schema
+	err    error
+}
+
+// Next moves to the next record and returns true if a record is present.
+func (r *TimeRangeReader) Next() bool {
+	r.index++
+	for r.index >= len(r.chunk.records) {
+		if !r.nextChunk() {
+			return false
+		}
+		r.index = 0
+	}
+	return true
+}
+
+// nextChunk releases the current window and waits for the next one.
+func (r *TimeRangeReader) nextChunk() bool {
+	if r.held {
+		releaseRecords(r.chunk.records)
+		r.chunk = timeRangeChunk{}
+		r.held = false
+		<-r.slots
+	}
+	if r.err != nil || r.received >= len(r.results) {
+		r.Release()
+		return false
+	}
+	chunk := <-r.results[r.received]
+	r.received++
+	if chunk.err != nil {
+		r.err = fmt.Errorf("time range chunk %d: %w", r.received-1, chunk.err)
+		r.Release()
+		return false
+	}
+	r.chunk = chunk
+	r.held = true
+	if r.schema == nil {
+		r.schema = chunk.schema
+	}
+	return true
+}
+
+// RecordBatch returns the current record.
+func (r *TimeRangeReader) RecordBatch() arrow.RecordBatch { //nolint:ireturn
+	return r.chunk.records[r.index]
+}
+
+// Err returns the first error of querying the windows.
+func (r *TimeRangeReader) Err() error {
+	return r.err
+}
+
+// Schema returns the schema of the first window.
+func (r *TimeRangeReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+// Release cancels the pending queries and releases the buffered records.
+// It is called automatically when the last record is read or an error occurs.
+func (r *TimeRangeReader) Release() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.cancel = nil
+	if r.held {
+		releaseRecords(r.chunk.records)
+		r.chunk = timeRangeChunk{}
+		r.held = false
+	}
+	pending := r.results[r.received:]
+	r.received = len(r.results)
+	if len(pending) > 0 {
+		// the canceled queries complete in the background
+		go func() {
+			for _, ch := range pending {
+				releaseRecords((<-ch).records)
+			}
+		}()
+	}
+}
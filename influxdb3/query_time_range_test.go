@@ -0,0 +1,208 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fake Flight server returning the $start and $end parameters of the query,
+// the windows listed in fail fail with the given code failures times, once by default
+type timeRangeFlightServer struct {
+	flight.BaseFlightServer
+	mu       sync.Mutex
+	fail     map[string]codes.Code
+	failures int
+	failed   map[string]int
+}
+
+func (f *timeRangeFlightServer) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
+	var ticket struct {
+		Params map[string]string `json:"params"`
+	}
+	if err := json.Unmarshal(tkt.GetTicket(), &ticket); err != nil {
+		return err
+	}
+	start, end := ticket.Params["start"], ticket.Params["end"]
+
+	f.mu.Lock()
+	code, fail := f.fail[start]
+	if f.failed == nil {
+		f.failed = make(map[string]int)
+	}
+	f.failed[start]++
+	if f.failed[start] >= max(f.failures, 1) {
+		delete(f.fail, start)
+	}
+	f.mu.Unlock()
+	if fail {
+		return status.Error(code, "failed")
+	}
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "start", Type: arrow.BinaryTypes.String},
+		{Name: "end", Type: arrow.BinaryTypes.String},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).Append(start)
+	builder.Field(1).(*array.StringBuilder).Append(end)
+	rec := builder.NewRecord()
+	defer rec.Release()
+	w := flight.NewRecordWriter(fs, ipc.WithSchema(schema))
+	return w.Write(rec)
+}
+
+func TestQueryTimeRange(t *testing.T) {
+	f := &timeRangeFlightServer{fail: map[string]codes.Code{"2026-01-01T02:00:00Z": codes.Unavailable}}
+	client := startFlightServer(t, f, nil)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reader, err := client.QueryTimeRange(context.Background(),
+		"SELECT * FROM cpu WHERE time >= $start AND time < $end", nil,
+		TimeRangeOptions{
+			Start:       start,
+			End:         start.Add(4 * time.Hour),
+			Chunks:      4,
+			Concurrency: 2,
+			Retry: QueryRetryOptions{
+				RetryOptions: RetryOptions{MaxAttempts: 2, InitialInterval: time.Millisecond},
+			},
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "start", reader.Schema().Field(0).Name)
+
+	it := NewQueryIteratorFromReader(reader)
+	var windows [][2]string
+	for it.Next() {
+		windows = append(windows, [2]string{it.Value()["start"].(string), it.Value()["end"].(string)})
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, [][2]string{
+		{"2026-01-01T00:00:00Z", "2026-01-01T01:00:00Z"},
+		{"2026-01-01T01:00:00Z", "2026-01-01T02:00:00Z"},
+		{"2026-01-01T02:00:00Z", "2026-01-01T03:00:00Z"},
+		{"2026-01-01T03:00:00Z", "2026-01-01T04:00:00Z"},
+	}, windows)
+}
+
+func TestQueryTimeRangeError(t *testing.T) {
+	f := &timeRangeFlightServer{fail: map[string]codes.Code{"2026-01-01T01:00:00Z": codes.InvalidArgument}}
+	client := startFlightServer(t, f, nil)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reader, err := client.QueryTimeRange(context.Background(), "SELECT 1", nil,
+		TimeRangeOptions{
+			Start:  start,
+			End:    start.Add(3 * time.Hour),
+			Chunks: 3,
+			Retry: QueryRetryOptions{
+				RetryOptions: RetryOptions{MaxAttempts: 3, InitialInterval: time.Millisecond},
+			},
+		})
+	require.NoError(t, err)
+
+	count := 0
+	for reader.Next() {
+		count++
+	}
+	// InvalidArgument is not retried, the first window is returned before the failure
+	assert.Equal(t, 1, count)
+	require.Error(t, reader.Err())
+	assert.Equal(t, codes.InvalidArgument, status.Code(reader.Err()))
+	assert.False(t, reader.Next())
+}
+
+func TestQueryTimeRangeRelease(t *testing.T) {
+	client := startFlightServer(t, &timeRangeFlightServer{}, nil)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reader, err := client.QueryTimeRange(context.Background(), "SELECT 1", QueryParameters{"host": "a"},
+		TimeRangeOptions{Start: start, End: start.Add(time.Hour), Chunks: 10, Concurrency: 1})
+	require.NoError(t, err)
+	require.True(t, reader.Next())
+	reader.Release()
+	assert.False(t, reader.Next())
+	assert.NoError(t, reader.Err())
+}
+
+func TestQueryTimeRangeInvalid(t *testing.T) {
+	c, err := New(ClientConfig{Host: "http://localhost:8086", Token: "my-token", Database: "db"})
+	require.NoError(t, err)
+	now := time.Now()
+	_, err = c.QueryTimeRange(context.Background(), "SELECT 1", nil, TimeRangeOptions{Start: now, End: now})
+	assert.EqualError(t, err, "time range end must be after start")
+}
+
+func TestQueryTimeRangeClientRetry(t *testing.T) {
+	f := &timeRangeFlightServer{fail: map[string]codes.Code{"2026-01-01T00:00:00Z": codes.Unavailable}}
+	client := startFlightServer(t, f, func(config *ClientConfig) {
+		config.QueryRetry = &QueryRetryOptions{
+			RetryOptions: RetryOptions{MaxAttempts: 2, InitialInterval: time.Millisecond},
+		}
+	})
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reader, err := client.QueryTimeRange(context.Background(), "SELECT 1", nil,
+		TimeRangeOptions{Start: start, End: start.Add(time.Hour)})
+	require.NoError(t, err)
+	require.True(t, reader.Next())
+	assert.False(t, reader.Next())
+	require.NoError(t, reader.Err())
+}
+
+func TestQueryTimeRangeRetryNotNested(t *testing.T) {
+	f := &timeRangeFlightServer{fail: map[string]codes.Code{"2026-01-01T00:00:00Z": codes.Unavailable}, failures: 2}
+	client := startFlightServer(t, f, func(config *ClientConfig) {
+		config.QueryRetry = &QueryRetryOptions{
+			RetryOptions: RetryOptions{MaxAttempts: 2, InitialInterval: time.Millisecond},
+		}
+	})
+
+	// both policies allow 2 attempts, the window is not queried 4 times
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := client.QueryTimeRange(context.Background(), "SELECT 1", nil,
+		TimeRangeOptions{
+			Start: start,
+			End:   start.Add(time.Hour),
+			Retry: QueryRetryOptions{
+				RetryOptions: RetryOptions{MaxAttempts: 2, InitialInterval: time.Millisecond},
+			},
+		})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
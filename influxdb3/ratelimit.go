@@ -0,0 +1,158 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit configures client-side throttling of write requests. Requests and
+// line protocol bytes are limited by token buckets, a write waits until both
+// buckets allow it or its context is done.
+type RateLimit struct {
+	// RequestsPerSecond is the maximum average rate of write requests. 0 for no limit.
+	RequestsPerSecond float64
+
+	// RequestBurst is the number of requests that can be sent at once.
+	// Default value: RequestsPerSecond rounded up, at least 1.
+	RequestBurst int
+
+	// BytesPerSecond is the maximum average rate of written line protocol bytes,
+	// counted before compression. 0 for no limit.
+	BytesPerSecond float64
+
+	// ByteBurst is the number of bytes that can be sent at once. A larger request
+	// is sent when the bucket is full and delays the following requests.
+	// Default value: BytesPerSecond rounded up, at least 1.
+	ByteBurst int
+}
+
+func (l *RateLimit) validate() error {
+	if l.RequestsPerSecond < 0 || l.BytesPerSecond < 0 || l.RequestBurst < 0 || l.ByteBurst < 0 {
+		return errors.New("invalid write rate limit: negative value")
+	}
+	return nil
+}
+
+// tokenBucket is a token bucket allowing to reserve tokens in advance. Reserving
+// more tokens than available makes the balance negative, the caller waits until it is refilled.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = max(int(math.Ceil(rate)), 1)
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes n tokens and returns the time to wait until they are available.
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	// a request larger than the burst waits for the full bucket only, but
+	// all its tokens are taken so that the following requests keep the rate
+	var wait time.Duration
+	if need := min(n, b.burst); b.tokens < need {
+		wait = time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+	}
+	b.tokens -= n
+	return wait
+}
+
+// cancel returns n reserved tokens.
+func (b *tokenBucket) cancel(n float64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+n)
+}
+
+// rateLimiter throttles write requests by count and size.
+type rateLimiter struct {
+	requests *tokenBucket
+	bytes    *tokenBucket
+}
+
+// newRateLimiter returns nil when limit does not restrict anything.
+func newRateLimiter(limit *RateLimit) *rateLimiter {
+	if limit == nil || (limit.RequestsPerSecond <= 0 && limit.BytesPerSecond <= 0) {
+		return nil
+	}
+	return &rateLimiter{
+		requests: newTokenBucket(limit.RequestsPerSecond, limit.RequestBurst),
+		bytes:    newTokenBucket(limit.BytesPerSecond, limit.ByteBurst),
+	}
+}
+
+// wait blocks until a request of size bytes is allowed. When ctx is done
+// first, the reservation is canceled and ctx.Err() is returned.
+func (l *rateLimiter) wait(ctx context.Context, size int) error {
+	if l == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d := max(l.requests.reserve(1), l.bytes.reserve(float64(size)))
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.requests.cancel(1)
+		l.bytes.cancel(float64(size))
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
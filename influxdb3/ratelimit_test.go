@@ -0,0 +1,157 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitValidate(t *testing.T) {
+	assert.NoError(t, (&RateLimit{}).validate())
+	assert.NoError(t, (&RateLimit{RequestsPerSecond: 10, BytesPerSecond: 1000}).validate())
+	assert.Error(t, (&RateLimit{RequestsPerSecond: -1}).validate())
+	assert.Error(t, (&RateLimit{BytesPerSecond: 1, ByteBurst: -1}).validate())
+
+	_, err := New(ClientConfig{
+		Host:           "http://localhost:8086",
+		Token:          "my-token",
+		WriteRateLimit: &RateLimit{RequestsPerSecond: -1},
+	})
+	assert.Error(t, err)
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	assert.Nil(t, newRateLimiter(nil))
+	assert.Nil(t, newRateLimiter(&RateLimit{}))
+
+	l := newRateLimiter(&RateLimit{RequestsPerSecond: 2.5})
+	require.NotNil(t, l)
+	assert.Nil(t, l.bytes)
+	assert.InDelta(t, 3, l.requests.burst, 0)
+
+	l = newRateLimiter(&RateLimit{BytesPerSecond: 100, ByteBurst: 10})
+	require.NotNil(t, l)
+	assert.Nil(t, l.requests)
+	assert.InDelta(t, 10, l.bytes.burst, 0)
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	b := newTokenBucket(10, 2)
+	assert.Zero(t, b.reserve(1))
+	assert.Zero(t, b.reserve(1))
+	// empty bucket, next token in 100ms
+	assert.InDelta(t, 100*time.Millisecond, b.reserve(1), float64(10*time.Millisecond))
+	b.cancel(1)
+
+	// larger than burst waits for full bucket only, but takes all tokens
+	b = newTokenBucket(10, 2)
+	assert.Zero(t, b.reserve(5))
+	assert.InDelta(t, 400*time.Millisecond, b.reserve(1), float64(10*time.Millisecond))
+}
+
+func TestRateLimiterWaitContextCanceled(t *testing.T) {
+	l := newRateLimiter(&RateLimit{RequestsPerSecond: 1})
+	require.NoError(t, l.wait(context.Background(), 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := l.wait(ctx, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	// reservation is returned, tokens are not consumed by canceled wait
+	assert.Less(t, l.requests.tokens, 0.1)
+	assert.Greater(t, l.requests.tokens, -0.1)
+
+	var nilLimiter *rateLimiter
+	assert.NoError(t, nilLimiter.wait(ctx, 100))
+}
+
+func TestWriteRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	c, err := New(ClientConfig{
+		Host:           ts.URL,
+		Token:          "my-token",
+		Database:       "my-database",
+		WriteRateLimit: &RateLimit{RequestsPerSecond: 20, RequestBurst: 1},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	start := time.Now()
+	for range 3 {
+		require.NoError(t, c.Write(context.Background(), []byte("cpu usage=1")))
+	}
+	// the first request uses the burst, the others wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestWriteRateLimitRetries(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	c, err := New(ClientConfig{
+		Host:           ts.URL,
+		Token:          "my-token",
+		Database:       "my-database",
+		WriteRateLimit: &RateLimit{RequestsPerSecond: 20, RequestBurst: 1},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	start := time.Now()
+	err = c.Write(context.Background(), []byte("cpu usage=1"),
+		WithWriteRetry(RetryOptions{MaxAttempts: 3, InitialInterval: time.Millisecond}))
+	require.NoError(t, err)
+	// every attempt is throttled, the retries wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Equal(t, int32(3), requests.Load())
+}
@@ -17,8 +17,9 @@ type RecordReader interface {
 
 // cancelingRecordReader is a RecordReader that cancels the context when done.
 type cancelingRecordReader struct {
-	reader *flight.Reader
-	cancel context.CancelFunc
+	reader   *flight.Reader
+	cancel   context.CancelFunc
+	released bool
 }
 
 func (cr *cancelingRecordReader) Next() bool {
@@ -43,3 +44,16 @@ func (cr *cancelingRecordReader) Schema() *arrow.Schema {
 func (cr *cancelingRecordReader) Reader() *flight.Reader {
 	return cr.reader
 }
+
+// Release releases the underlying reader and cancels the context.
+// It is safe to call Release more than once.
+func (cr *cancelingRecordReader) Release() {
+	if !cr.released {
+		cr.released = true
+		cr.reader.Release()
+	}
+	if cr.cancel != nil {
+		cr.cancel()
+		cr.cancel = nil
+	}
+}
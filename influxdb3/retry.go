@@ -0,0 +1,193 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryOptions holds the retry policy applied to failed write requests.
+// The zero value disables retrying.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value less than 2 disables retrying.
+	MaxAttempts int
+
+	// InitialInterval is the delay before the first retry.
+	// Default value: 1 second.
+	InitialInterval time.Duration
+
+	// MaxInterval is the upper bound of the delay between two attempts.
+	// Default value: 30 seconds.
+	MaxInterval time.Duration
+
+	// Multiplier is the factor the delay grows by after each attempt.
+	// Default value: 2.
+	Multiplier float64
+
+	// Jitter is the randomization factor in the range [0, 1] added to each delay.
+	// For example, 0.2 produces delays between 100% and 120% of the computed value.
+	Jitter float64
+
+	// MaxElapsedTime limits the total time spent retrying a single write.
+	// No further attempt is made when the next delay would exceed it. 0 means no limit.
+	MaxElapsedTime time.Duration
+
+	// RetryableStatusCodes lists the HTTP status codes that cause a write to be retried.
+	// Default value: 429, 502, 503, 504.
+	RetryableStatusCodes []int
+}
+
+const (
+	// defaultRetryInitialInterval specifies the default value of RetryOptions.InitialInterval.
+	defaultRetryInitialInterval = time.Second
+	// defaultRetryMaxInterval specifies the default value of RetryOptions.MaxInterval.
+	defaultRetryMaxInterval = 30 * time.Second
+	// defaultRetryMultiplier specifies the default value of RetryOptions.Multiplier.
+	defaultRetryMultiplier = 2.0
+)
+
+// defaultRetryableStatusCodes specifies the default value of RetryOptions.RetryableStatusCodes.
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryOptions specifies a reasonable retry policy which can be enabled with WithWriteRetry.
+var DefaultRetryOptions = RetryOptions{
+	MaxAttempts:     5,
+	InitialInterval: defaultRetryInitialInterval,
+	MaxInterval:     defaultRetryMaxInterval,
+	Multiplier:      defaultRetryMultiplier,
+	Jitter:          0.2,
+	MaxElapsedTime:  3 * time.Minute,
+}
+
+// enabled reports whether the policy allows more than a single attempt.
+func (r *RetryOptions) enabled() bool {
+	return r.MaxAttempts > 1
+}
+
+// isRetryable reports whether the write failure err may succeed when repeated.
+// Server errors are retried according to RetryableStatusCodes, transport errors
+// are always retried unless the context is done.
+func (r *RetryOptions) isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var svErr *ServerError
+	if errors.As(err, &svErr) {
+		codes := r.RetryableStatusCodes
+		if codes == nil {
+			codes = defaultRetryableStatusCodes
+		}
+		return slices.Contains(codes, svErr.StatusCode)
+	}
+	return true
+}
+
+// delay computes the wait time before the attempt following attempt (1-based).
+// Retry-After sent by the server takes precedence over the computed backoff.
+func (r *RetryOptions) delay(attempt int, err error) time.Duration {
+	var svErr *ServerError
+	if errors.As(err, &svErr) && svErr.RetryAfter > 0 {
+		return time.Duration(svErr.RetryAfter) * time.Second
+	}
+
+	initial := r.InitialInterval
+	if initial <= 0 {
+		initial = defaultRetryInitialInterval
+	}
+	maxInterval := r.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultRetryMaxInterval
+	}
+	multiplier := r.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultRetryMultiplier
+	}
+
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	d = min(d, float64(maxInterval))
+	if r.Jitter > 0 {
+		d += d * min(r.Jitter, 1) * rand.Float64() //nolint:gosec
+	}
+	return time.Duration(d)
+}
+
+// makeAPICallWithRetry issues the request described by params and repeats it
+// according to the retry policy. The request body must be an io.Seeker to be replayed.
+// Each attempt waits for the write rate limit.
+// The error of the last attempt is returned when all attempts fail.
+func (c *Client) makeAPICallWithRetry(ctx context.Context, params httpParams, retry *RetryOptions) (*http.Response, error) {
+	if retry == nil || !retry.enabled() {
+		if err := c.rateLimiter.wait(ctx, params.size); err != nil {
+			return nil, err
+		}
+		return c.makeAPICall(ctx, params)
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		if seeker, ok := params.body.(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.rateLimiter.wait(ctx, params.size); err != nil {
+			return nil, err
+		}
+		resp, err := c.makeAPICall(ctx, params)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= retry.MaxAttempts || !retry.isRetryable(ctx, err) {
+			return nil, err
+		}
+		if _, ok := params.body.(io.Seeker); !ok && params.body != nil {
+			return nil, err
+		}
+
+		d := retry.delay(attempt, err)
+		if retry.MaxElapsedTime > 0 && time.Since(start)+d > retry.MaxElapsedTime {
+			return nil, err
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,229 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryOptionsDelay(t *testing.T) {
+	retry := RetryOptions{
+		MaxAttempts:     5,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     time.Second,
+		Multiplier:      2,
+	}
+	assert.Equal(t, 100*time.Millisecond, retry.delay(1, errors.New("x")))
+	assert.Equal(t, 200*time.Millisecond, retry.delay(2, errors.New("x")))
+	assert.Equal(t, 400*time.Millisecond, retry.delay(3, errors.New("x")))
+	assert.Equal(t, time.Second, retry.delay(10, errors.New("x")))
+
+	// Retry-After takes precedence
+	assert.Equal(t, 3*time.Second, retry.delay(1, &ServerError{StatusCode: 429, RetryAfter: 3}))
+
+	retry.Jitter = 0.5
+	for range 100 {
+		d := retry.delay(1, errors.New("x"))
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, 150*time.Millisecond)
+	}
+}
+
+func TestRetryOptionsIsRetryable(t *testing.T) {
+	ctx := context.Background()
+	retry := RetryOptions{MaxAttempts: 2}
+	assert.True(t, retry.isRetryable(ctx, &ServerError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, retry.isRetryable(ctx, &ServerError{StatusCode: http.StatusServiceUnavailable}))
+	assert.False(t, retry.isRetryable(ctx, &ServerError{StatusCode: http.StatusBadRequest}))
+	assert.False(t, retry.isRetryable(ctx, &PartialWriteError{ServerError: ServerError{StatusCode: http.StatusBadRequest}}))
+	assert.True(t, retry.isRetryable(ctx, errors.New("connection refused")))
+	assert.False(t, retry.isRetryable(ctx, context.DeadlineExceeded))
+
+	retry.RetryableStatusCodes = []int{http.StatusInternalServerError}
+	assert.True(t, retry.isRetryable(ctx, &ServerError{StatusCode: http.StatusInternalServerError}))
+	assert.False(t, retry.isRetryable(ctx, &ServerError{StatusCode: http.StatusServiceUnavailable}))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, retry.isRetryable(canceled, &ServerError{StatusCode: http.StatusServiceUnavailable}))
+}
+
+func TestWriteRetry(t *testing.T) {
+	lp := []byte("cpu,host=local usage_user=16.75\n")
+	for _, gzipThreshold := range []int{0, 1} {
+		var attempts atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// initialization of query client
+			if r.Method == "PRI" {
+				return
+			}
+			body := r.Body
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				body, _ = gzip.NewReader(body)
+			}
+			b, err := io.ReadAll(body)
+			assert.NoError(t, err)
+			assert.Equal(t, lp, b)
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		c, err := New(ClientConfig{
+			Host:     ts.URL,
+			Token:    "my-token",
+			Database: "my-database",
+		})
+		require.NoError(t, err)
+		err = c.Write(context.Background(), lp,
+			WithGzipThreshold(gzipThreshold),
+			WithWriteRetry(RetryOptions{MaxAttempts: 3, InitialInterval: time.Millisecond}))
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), attempts.Load())
+		ts.Close()
+	}
+}
+
+func TestWriteRetryExhausted(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		attempts.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+	err = c.Write(context.Background(), []byte("a f=1"),
+		WithWriteRetry(RetryOptions{MaxAttempts: 4, InitialInterval: time.Millisecond}))
+	var svErr *ServerError
+	require.ErrorAs(t, err, &svErr)
+	assert.Equal(t, http.StatusTooManyRequests, svErr.StatusCode)
+	assert.Equal(t, int32(4), attempts.Load())
+}
+
+func TestWriteRetryNotRetryable(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+		WriteOptions: &WriteOptions{
+			Precision: Nanosecond,
+			UseV2Api:  true,
+			Retry:     RetryOptions{MaxAttempts: 4, InitialInterval: time.Millisecond},
+		},
+	})
+	require.NoError(t, err)
+	err = c.Write(context.Background(), []byte("a f=1"))
+	require.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWriteRetryHonorsRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+	start := time.Now()
+	err = c.Write(context.Background(), []byte("a f=1"),
+		WithWriteRetry(RetryOptions{MaxAttempts: 2, InitialInterval: time.Millisecond}))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), attempts.Load())
+
+	// Retry-After exceeding the elapsed time budget stops retrying
+	attempts.Store(0)
+	err = c.Write(context.Background(), []byte("a f=1"),
+		WithWriteRetry(RetryOptions{MaxAttempts: 2, MaxElapsedTime: 100 * time.Millisecond}))
+	require.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWriteRetryContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = c.Write(ctx, []byte("a f=1"),
+		WithWriteRetry(RetryOptions{MaxAttempts: 10, InitialInterval: time.Minute}))
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
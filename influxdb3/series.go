@@ -0,0 +1,285 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// defaultSeriesBufferedRows specifies the default value of SeriesOptions.MaxBufferedRows.
+const defaultSeriesBufferedRows = 100_000
+
+// Series is a group of rows of the same measurement and tag set, as returned by the InfluxDB v1 and v2 APIs.
+type Series struct {
+	// Measurement of the series, empty when the result has no measurement column.
+	Measurement string
+	// Tags of the series, NULL tags are omitted.
+	Tags map[string]string
+	// Columns are the names of the non-tag columns, in the order of the result.
+	Columns []string
+	// Values are the rows of the series, one value per column. Timestamps are returned as time.Time,
+	// NULL values as nil.
+	Values [][]any
+}
+
+// SeriesOptions configures grouping of rows by NewSeriesIterator.
+type SeriesOptions struct {
+	// Sorted tells that the rows of each series are returned next to each other, e.g. by ORDER BY
+	// of all tags. Series are then returned in streaming mode as soon as the next series starts.
+	// A series is returned more than once when its rows are not adjacent.
+	Sorted bool
+
+	// MaxBufferedRows is the maximum number of rows buffered to group unsorted results.
+	// When reached, the buffered series are returned and grouping starts again,
+	// so that a series can be returned more than once.
+	// Default value: 100000.
+	MaxBufferedRows int
+}
+
+// SeriesIterator groups the rows of a QueryIterator by series. The series key of a row is computed
+// from its measurement and the columns marked as tags by the iox::column::type metadata.
+type SeriesIterator struct {
+	it      *QueryIterator
+	options SeriesOptions
+	// Layout of the current schema
+	schema      *arrow.Schema
+	measurement int
+	tags        []int
+	values      []int
+	columns     []string
+	// Series being built in streaming mode
+	current    *Series
+	currentKey string
+	// Series being built in buffered mode, in the order of their first row
+	groups   map[string]*Series
+	order    []*Series
+	buffered int
+	// Completed series
+	ready []*Series
+	err   error
+	done  bool
+	key   strings.Builder
+}
+
+// NewSeriesIterator returns a SeriesIterator reading the rows of it. The QueryIterator must not be
+// consumed by other means.
+func NewSeriesIterator(it *QueryIterator, options SeriesOptions) *SeriesIterator {
+	if options.MaxBufferedRows <= 0 {
+		options.MaxBufferedRows = defaultSeriesBufferedRows
+	}
+	return &SeriesIterator{
+		it:      it,
+		options: options,
+		groups:  make(map[string]*Series),
+	}
+}
+
+// Next returns the next series.
+// Its second return value is iterator.Done if there are no more results.
+// Once Next returns Done in the second parameter, all subsequent calls will return Done.
+//
+//	it := influxdb3.NewSeriesIterator(iterator, influxdb3.SeriesOptions{Sorted: true})
+//	for {
+//		series, err := it.Next()
+//		if err == influxdb3.Done {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		process(series)
+//	}
+func (s *SeriesIterator) Next() (*Series, error) {
+	for len(s.ready) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		if s.done {
+			return nil, Done
+		}
+
+		batch := s.it.NextBatch()
+		if batch == nil {
+			s.done = true
+			s.err = s.it.Err()
+			s.flush()
+			continue
+		}
+		record := batch.Record()
+		if s.schema == nil || !s.schema.Equal(record.Schema()) {
+			s.flush()
+			s.bind(record.Schema())
+		}
+		for row := range batch.NumRows() {
+			if err := s.add(record, row); err != nil {
+				s.err = err
+				break
+			}
+		}
+	}
+
+	series := s.ready[0]
+	s.ready[0] = nil
+	s.ready = s.ready[1:]
+	return series, nil
+}
+
+// Close cancels the query and releases the received data, see QueryIterator.Close.
+func (s *SeriesIterator) Close() error {
+	s.done = true
+	s.ready = nil
+	s.current = nil
+	s.groups = make(map[string]*Series)
+	s.order = nil
+	return s.it.Close()
+}
+
+// bind computes the role of the columns of schema.
+func (s *SeriesIterator) bind(schema *arrow.Schema) {
+	s.schema = schema
+	s.measurement = -1
+	s.tags = s.tags[:0]
+	s.values = s.values[:0]
+	s.columns = nil
+	for i, f := range schema.Fields() {
+		switch {
+		case (f.Name == "measurement" || f.Name == "iox::measurement") && f.Type.ID() == arrow.STRING:
+			s.measurement = i
+		case columnRole(f) == ColumnRoleTag:
+			s.tags = append(s.tags, i)
+		default:
+			s.values = append(s.values, i)
+			s.columns = append(s.columns, f.Name)
+		}
+	}
+	// the series key does not depend on the order of tag columns
+	slices.SortFunc(s.tags, func(a, b int) int {
+		return strings.Compare(schema.Field(a).Name, schema.Field(b).Name)
+	})
+}
+
+// add adds a row to the series it belongs to.
+func (s *SeriesIterator) add(record arrow.RecordBatch, row int) error {
+	measurement, err := s.stringValue(record, s.measurement, row)
+	if err != nil {
+		return err
+	}
+	s.key.Reset()
+	s.key.WriteString(measurement)
+	for _, ci := range s.tags {
+		value, err := s.stringValue(record, ci, row)
+		if err != nil {
+			return err
+		}
+		if record.Column(ci).IsNull(row) {
+			continue
+		}
+		s.key.WriteByte(0)
+		s.key.WriteString(s.schema.Field(ci).Name)
+		s.key.WriteByte(0)
+		s.key.WriteString(value)
+	}
+	key := s.key.String()
+
+	var series *Series
+	if s.options.Sorted {
+		if s.current == nil || key != s.currentKey {
+			if s.current != nil {
+				s.ready = append(s.ready, s.current)
+			}
+			s.current, s.currentKey = s.newSeries(record, row, measurement), key
+		}
+		series = s.current
+	} else {
+		series = s.groups[key]
+		if series == nil {
+			series = s.newSeries(record, row, measurement)
+			s.groups[key] = series
+			s.order = append(s.order, series)
+		}
+	}
+
+	values := make([]any, len(s.values))
+	for i, ci := range s.values {
+		if values[i], err = exportValue(record.Column(ci), s.schema.Field(ci), row); err != nil {
+			return err
+		}
+	}
+	series.Values = append(series.Values, values)
+
+	if !s.options.Sorted {
+		s.buffered++
+		if s.buffered >= s.options.MaxBufferedRows {
+			s.flush()
+		}
+	}
+	return nil
+}
+
+func (s *SeriesIterator) newSeries(record arrow.RecordBatch, row int, measurement string) *Series {
+	tags := make(map[string]string, len(s.tags))
+	for _, ci := range s.tags {
+		if value, err := s.stringValue(record, ci, row); err == nil && !record.Column(ci).IsNull(row) {
+			tags[s.schema.Field(ci).Name] = value
+		}
+	}
+	return &Series{
+		Measurement: measurement,
+		Tags:        tags,
+		Columns:     s.columns,
+	}
+}
+
+// stringValue returns the value of a string column, an empty string for NULL or when ci is negative.
+func (s *SeriesIterator) stringValue(record arrow.RecordBatch, ci int, row int) (string, error) {
+	if ci < 0 {
+		return "", nil
+	}
+	value, err := exportValue(record.Column(ci), s.schema.Field(ci), row)
+	if err != nil || value == nil {
+		return "", err
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("column '%s': series tag must be a string, got %T", s.schema.Field(ci).Name, value)
+	}
+	return str, nil
+}
+
+// flush moves the series being built to the completed series.
+func (s *SeriesIterator) flush() {
+	if s.current != nil {
+		s.ready = append(s.ready, s.current)
+		s.current = nil
+	}
+	if len(s.order) > 0 {
+		s.ready = append(s.ready, s.order...)
+		s.order = nil
+		clear(s.groups)
+	}
+	s.buffered = 0
+}
@@ -0,0 +1,163 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seriesRecord(hosts []string, regions []string, values []float64) arrow.RecordBatch {
+	tag := arrow.NewMetadata([]string{"iox::column::type"}, []string{"iox::column_type::tag"})
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "iox::measurement", Type: arrow.BinaryTypes.String},
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+		{Name: "region", Type: arrow.BinaryTypes.String, Metadata: tag, Nullable: true},
+		{Name: "host", Type: arrow.BinaryTypes.String, Metadata: tag},
+		{Name: "usage", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rb.Release()
+	for i := range hosts {
+		rb.Field(0).(*array.StringBuilder).Append("cpu")
+		rb.Field(1).(*array.TimestampBuilder).Append(arrow.Timestamp(i))
+		if regions[i] == "" {
+			rb.Field(2).AppendNull()
+		} else {
+			rb.Field(2).(*array.StringBuilder).Append(regions[i])
+		}
+		rb.Field(3).(*array.StringBuilder).Append(hosts[i])
+		rb.Field(4).(*array.Float64Builder).Append(values[i])
+	}
+	return rb.NewRecord()
+}
+
+func readSeries(t *testing.T, it *SeriesIterator) []*Series {
+	t.Helper()
+	var result []*Series
+	for {
+		series, err := it.Next()
+		if err == Done {
+			break
+		}
+		require.NoError(t, err)
+		result = append(result, series)
+	}
+	return result
+}
+
+func TestSeriesIteratorSorted(t *testing.T) {
+	rec1 := seriesRecord([]string{"a", "a", "b"}, []string{"us", "us", "us"}, []float64{1, 2, 3})
+	defer rec1.Release()
+	rec2 := seriesRecord([]string{"b", "c"}, []string{"us", ""}, []float64{4, 5})
+	defer rec2.Release()
+
+	it := NewSeriesIterator(NewQueryIteratorFromReader(&recordsReader{records: []arrow.RecordBatch{rec1, rec2}}),
+		SeriesOptions{Sorted: true})
+	series := readSeries(t, it)
+	require.Len(t, series, 3)
+
+	assert.Equal(t, "cpu", series[0].Measurement)
+	assert.Equal(t, map[string]string{"host": "a", "region": "us"}, series[0].Tags)
+	assert.Equal(t, []string{"time", "usage"}, series[0].Columns)
+	assert.Equal(t, [][]any{
+		{time.Unix(0, 0), 1.0},
+		{time.Unix(0, 1), 2.0},
+	}, normalizeSeriesTimes(series[0].Values))
+
+	// a series continues in the next record batch
+	assert.Equal(t, map[string]string{"host": "b", "region": "us"}, series[1].Tags)
+	assert.Len(t, series[1].Values, 2)
+
+	// NULL tags are omitted
+	assert.Equal(t, map[string]string{"host": "c"}, series[2].Tags)
+	assert.Equal(t, 5.0, series[2].Values[0][1])
+
+	_, err := it.Next()
+	assert.Equal(t, Done, err)
+}
+
+func TestSeriesIteratorUnsorted(t *testing.T) {
+	rec := seriesRecord([]string{"a", "b", "a", "b", "a"}, []string{"us", "us", "us", "us", "us"}, []float64{1, 2, 3, 4, 5})
+	defer rec.Release()
+
+	it := NewSeriesIterator(NewQueryIteratorFromReader(&recordsReader{records: []arrow.RecordBatch{rec}}), SeriesOptions{})
+	series := readSeries(t, it)
+	require.Len(t, series, 2)
+	assert.Equal(t, "a", series[0].Tags["host"])
+	assert.Equal(t, []any{1.0, 3.0, 5.0}, columnValues(series[0], 1))
+	assert.Equal(t, "b", series[1].Tags["host"])
+	assert.Equal(t, []any{2.0, 4.0}, columnValues(series[1], 1))
+}
+
+func TestSeriesIteratorBufferLimit(t *testing.T) {
+	rec := seriesRecord([]string{"a", "b", "a", "b", "a"}, []string{"us", "us", "us", "us", "us"}, []float64{1, 2, 3, 4, 5})
+	defer rec.Release()
+
+	it := NewSeriesIterator(NewQueryIteratorFromReader(&recordsReader{records: []arrow.RecordBatch{rec}}),
+		SeriesOptions{MaxBufferedRows: 3})
+	series := readSeries(t, it)
+	require.Len(t, series, 4)
+	assert.Equal(t, []any{1.0, 3.0}, columnValues(series[0], 1))
+	assert.Equal(t, []any{2.0}, columnValues(series[1], 1))
+	assert.Equal(t, []any{4.0}, columnValues(series[2], 1))
+	assert.Equal(t, []any{5.0}, columnValues(series[3], 1))
+}
+
+func TestSeriesIteratorClose(t *testing.T) {
+	rec := seriesRecord([]string{"a", "b"}, []string{"us", "us"}, []float64{1, 2})
+	defer rec.Release()
+
+	it := NewSeriesIterator(NewQueryIteratorFromReader(&recordsReader{records: []arrow.RecordBatch{rec}}),
+		SeriesOptions{Sorted: true})
+	_, err := it.Next()
+	require.NoError(t, err)
+	require.NoError(t, it.Close())
+	_, err = it.Next()
+	assert.Equal(t, Done, err)
+}
+
+func columnValues(series *Series, column int) []any {
+	values := make([]any, len(series.Values))
+	for i, row := range series.Values {
+		values[i] = row[column]
+	}
+	return values
+}
+
+func normalizeSeriesTimes(values [][]any) [][]any {
+	for _, row := range values {
+		for i, v := range row {
+			if ts, ok := v.(time.Time); ok {
+				row[i] = time.Unix(0, ts.UnixNano())
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,513 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSpoolMaxSize specifies the default value of SpoolOptions.MaxSize.
+	defaultSpoolMaxSize = 1 << 30
+	// defaultSpoolSegmentSize specifies the default value of SpoolOptions.SegmentSize.
+	defaultSpoolSegmentSize = 16 << 20
+	// defaultSpoolReplayInterval specifies the default value of SpoolOptions.ReplayInterval.
+	defaultSpoolReplayInterval = 10 * time.Second
+	// spoolSegmentExt is the file name extension of spool segments
+	spoolSegmentExt = ".lps"
+	// spoolRecordHeaderSize is the size of record header: meta length, body length, checksum
+	spoolRecordHeaderSize = 12
+)
+
+// ErrSpoolFull is returned when a batch cannot be stored because the spool reached SpoolOptions.MaxSize.
+var ErrSpoolFull = errors.New("write spool is full")
+
+// SpoolDropPolicy specifies which data is discarded when the spool is full.
+type SpoolDropPolicy int
+
+const (
+	// SpoolDropOldest removes the oldest segments to make room for new batches.
+	SpoolDropOldest SpoolDropPolicy = iota
+	// SpoolDropNewest keeps the stored data and rejects new batches.
+	SpoolDropNewest
+)
+
+// SpoolOptions configures the disk-backed spool storing batches that could not be written.
+//
+// When a write fails with an error that can be retried (see RetryOptions.RetryableStatusCodes),
+// the batch is stored in segment files in Dir and the write returns no error. While the spool is not
+// empty, new batches are appended to it as well, so that data is written in order. A background
+// goroutine replays the stored batches when the server becomes reachable. Segments left over
+// by a previous process are replayed too.
+//
+// New batches are stored on disk until the spool is replayed, which happens every ReplayInterval.
+// Once the server is reachable again, it can thus take up to ReplayInterval before the writes are
+// sent directly. Call Client.ReplaySpool to replay the stored batches without waiting.
+//
+// A batch may be written more than once when the process stops during replay.
+type SpoolOptions struct {
+	// Dir is the directory holding the segment files. It is created if it does not exist.
+	Dir string
+
+	// MaxSize is the maximum size of all segment files in bytes.
+	// Default value: 1 GiB.
+	MaxSize int64
+
+	// SegmentSize is the size in bytes after which a new segment file is started.
+	// Default value: 16 MiB.
+	SegmentSize int64
+
+	// DropPolicy specifies which data is discarded when MaxSize is reached.
+	// Default value: SpoolDropOldest.
+	DropPolicy SpoolDropPolicy
+
+	// ReplayInterval is the interval of attempts to write the stored batches.
+	// Default value: 10 seconds.
+	ReplayInterval time.Duration
+}
+
+// spoolRecordMeta holds the write options needed to replay a stored batch.
+type spoolRecordMeta struct {
+	Database      string    `json:"database"`
+	Precision     Precision `json:"precision"`
+	GzipThreshold int       `json:"gzipThreshold"`
+	NoSync        bool      `json:"noSync"`
+	AcceptPartial bool      `json:"acceptPartial"`
+	UseV2Api      bool      `json:"useV2Api"`
+	// compression fields are omitted for the default gzip compression
+	CompressionCodec CompressionCodec `json:"compressionCodec,omitempty"`
+	CompressionLevel *int             `json:"compressionLevel,omitempty"`
+}
+
+func newSpoolRecordMeta(database string, options *WriteOptions) spoolRecordMeta {
+	return spoolRecordMeta{
+		Database:         database,
+		Precision:        options.Precision,
+		GzipThreshold:    options.GzipThreshold,
+		NoSync:           options.NoSync,
+		AcceptPartial:    options.AcceptPartial,
+		UseV2Api:         options.UseV2Api,
+		CompressionCodec: options.Compression.Codec,
+		CompressionLevel: options.Compression.Level,
+	}
+}
+
+func (m spoolRecordMeta) writeOptions() *WriteOptions {
+	return &WriteOptions{
+		Database:      m.Database,
+		Precision:     m.Precision,
+		GzipThreshold: m.GzipThreshold,
+		NoSync:        m.NoSync,
+		AcceptPartial: m.AcceptPartial,
+		UseV2Api:      m.UseV2Api,
+		Compression: Compression{
+			Codec: m.CompressionCodec,
+			Level: m.CompressionLevel,
+		},
+	}
+}
+
+// spoolRecord is a single stored batch of line protocol.
+type spoolRecord struct {
+	meta spoolRecordMeta
+	body []byte
+}
+
+// spoolSegment is a file holding a sequence of records.
+// Each record consists of a header with the length of the JSON encoded meta, the length of the body
+// and a CRC-32 checksum of both, followed by the meta and the body.
+type spoolSegment struct {
+	seq  uint64
+	path string
+	size int64
+	// number of records already replayed
+	delivered int
+}
+
+// spool stores batches in segment files.
+type spool struct {
+	options SpoolOptions
+
+	mu         sync.Mutex
+	segments   []*spoolSegment // oldest first
+	active     *os.File        // open last segment, nil when sealed
+	nextSeq    uint64
+	size       int64
+	replayMu   sync.Mutex
+	stop       chan struct{}
+	cancel     context.CancelFunc
+	replayDone sync.WaitGroup
+}
+
+// openSpool opens the spool directory and loads the segments left by previous runs.
+func openSpool(options SpoolOptions) (*spool, error) {
+	if options.Dir == "" {
+		return nil, errors.New("spool directory not specified")
+	}
+	if options.MaxSize <= 0 {
+		options.MaxSize = defaultSpoolMaxSize
+	}
+	if options.SegmentSize <= 0 {
+		options.SegmentSize = defaultSpoolSegmentSize
+	}
+	if options.ReplayInterval <= 0 {
+		options.ReplayInterval = defaultSpoolReplayInterval
+	}
+	if err := os.MkdirAll(options.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("spool: %w", err)
+	}
+	entries, err := os.ReadDir(options.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("spool: %w", err)
+	}
+
+	s := &spool{options: options}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("spool: %w", err)
+		}
+		s.segments = append(s.segments, &spoolSegment{seq: seq, path: filepath.Join(options.Dir, name), size: info.Size()})
+		s.size += info.Size()
+		s.nextSeq = max(s.nextSeq, seq+1)
+	}
+	slices.SortFunc(s.segments, func(a, b *spoolSegment) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	return s, nil
+}
+
+// empty reports whether there are no stored batches.
+func (s *spool) empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.segments) == 0
+}
+
+// bytes returns the total size of the segment files.
+func (s *spool) bytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// append stores the record at the end of the spool, applying the drop policy when it is full.
+func (s *spool) append(record spoolRecord) error {
+	meta, err := json.Marshal(record.meta)
+	if err != nil {
+		return fmt.Errorf("spool: %w", err)
+	}
+	data := make([]byte, spoolRecordHeaderSize, spoolRecordHeaderSize+len(meta)+len(record.body))
+	data = append(data, meta...)
+	data = append(data, record.body...)
+	binary.BigEndian.PutUint32(data[0:4], uint32(len(meta)))        //nolint:gosec
+	binary.BigEndian.PutUint32(data[4:8], uint32(len(record.body))) //nolint:gosec
+	binary.BigEndian.PutUint32(data[8:12], crc32.ChecksumIEEE(data[spoolRecordHeaderSize:]))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := int64(len(data))
+	if size > s.options.MaxSize {
+		return ErrSpoolFull
+	}
+	for s.size+size > s.options.MaxSize {
+		if s.options.DropPolicy == SpoolDropNewest || len(s.segments) == 0 {
+			return ErrSpoolFull
+		}
+		oldest := s.segments[0]
+		if len(s.segments) == 1 {
+			s.seal()
+		}
+		slog.Warn(fmt.Sprintf("Write spool is full, dropping segment %s", oldest.path))
+		s.removeLocked(oldest)
+	}
+
+	if s.active == nil || s.segments[len(s.segments)-1].size+size > s.options.SegmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.active.Write(data); err != nil {
+		return fmt.Errorf("spool: %w", err)
+	}
+	if err := s.active.Sync(); err != nil {
+		return fmt.Errorf("spool: %w", err)
+	}
+	s.segments[len(s.segments)-1].size += size
+	s.size += size
+
+	return nil
+}
+
+// rotate seals the active segment and starts a new one.
+func (s *spool) rotate() error {
+	s.seal()
+	seg := &spoolSegment{
+		seq:  s.nextSeq,
+		path: filepath.Join(s.options.Dir, fmt.Sprintf("%020d%s", s.nextSeq, spoolSegmentExt)),
+	}
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("spool: %w", err)
+	}
+	s.nextSeq++
+	s.active = f
+	s.segments = append(s.segments, seg)
+	return nil
+}
+
+// seal closes the active segment, so that no more records are appended to it.
+func (s *spool) seal() {
+	if s.active != nil {
+		_ = s.active.Close()
+		s.active = nil
+	}
+}
+
+// oldest returns the oldest segment sealing it if needed, or nil if the spool is empty.
+func (s *spool) oldest() *spoolSegment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.segments) == 0 {
+		return nil
+	}
+	if len(s.segments) == 1 {
+		s.seal()
+	}
+	return s.segments[0]
+}
+
+// remove deletes a replayed segment.
+func (s *spool) remove(seg *spoolSegment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(seg)
+}
+
+func (s *spool) removeLocked(seg *spoolSegment) {
+	i := slices.Index(s.segments, seg)
+	if i < 0 {
+		return
+	}
+	s.segments = slices.Delete(s.segments, i, i+1)
+	s.size -= seg.size
+	if err := os.Remove(seg.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn(fmt.Sprintf("Cannot remove write spool segment %s: %v", seg.path, err))
+	}
+}
+
+// close seals the active segment.
+func (s *spool) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seal()
+}
+
+// readSpoolSegment reads all records of a segment file. When a record is corrupted,
+// the records read so far are returned together with an error.
+func readSpoolSegment(path string) ([]spoolRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// remaining bounds the record lengths, a corrupted header must not cause a huge allocation
+	remaining := info.Size()
+
+	var records []spoolRecord
+	r := bufio.NewReader(f)
+	header := make([]byte, spoolRecordHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return records, fmt.Errorf("truncated record header: %w", err)
+		}
+		metaLen := binary.BigEndian.Uint32(header[0:4])
+		bodyLen := binary.BigEndian.Uint32(header[4:8])
+		remaining -= spoolRecordHeaderSize
+		if int64(metaLen)+int64(bodyLen) > remaining {
+			return records, fmt.Errorf("truncated record: %w", io.ErrUnexpectedEOF)
+		}
+		remaining -= int64(metaLen) + int64(bodyLen)
+		data := make([]byte, int(metaLen)+int(bodyLen))
+		if _, err := io.ReadFull(r, data); err != nil {
+			return records, fmt.Errorf("truncated record: %w", err)
+		}
+		if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[8:12]) {
+			return records, errors.New("checksum mismatch")
+		}
+		var record spoolRecord
+		if err := json.Unmarshal(data[:metaLen], &record.meta); err != nil {
+			return records, fmt.Errorf("invalid record: %w", err)
+		}
+		record.body = data[metaLen:]
+		records = append(records, record)
+	}
+}
+
+// startSpoolReplay starts the goroutine replaying the stored batches periodically.
+func (c *Client) startSpoolReplay() {
+	s := c.spool
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.stop = make(chan struct{})
+	s.replayDone.Add(1)
+	go func() {
+		defer s.replayDone.Done()
+		ticker := time.NewTicker(s.options.ReplayInterval)
+		defer ticker.Stop()
+		for {
+			if !s.empty() {
+				if err := c.ReplaySpool(ctx); err != nil {
+					slog.Debug(fmt.Sprintf("Write spool replay failed: %v", err))
+				}
+			}
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopSpoolReplay stops the replay goroutine and closes the spool.
+func (c *Client) stopSpoolReplay() {
+	s := c.spool
+	if s.stop != nil {
+		close(s.stop)
+		s.cancel()
+		s.replayDone.Wait()
+		s.stop = nil
+	}
+	s.close()
+}
+
+// ReplaySpool writes the batches stored in the write spool in order, see ClientConfig.Spool.
+// Batches rejected by the server with an error that cannot be retried are dropped.
+// It returns nil when the spool is empty, or the error which stopped the replay.
+//
+// Batches are also replayed periodically in the background, so calling ReplaySpool is optional.
+func (c *Client) ReplaySpool(ctx context.Context) error {
+	s := c.spool
+	if s == nil {
+		return nil
+	}
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	for {
+		seg := s.oldest()
+		if seg == nil {
+			return nil
+		}
+		records, readErr := readSpoolSegment(seg.path)
+		if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+			slog.Warn(fmt.Sprintf("Write spool segment %s is corrupted, %d batches recovered: %v", seg.path, len(records), readErr))
+		}
+		for ; seg.delivered < len(records); seg.delivered++ {
+			record := records[seg.delivered]
+			options := record.meta.writeOptions()
+			params, err := c.makeHTTPParams(record.body, options)
+			if err == nil {
+				err = c.rateLimiter.wait(ctx, len(record.body))
+			}
+			if err == nil {
+				var resp *http.Response
+				resp, err = c.makeAPICall(ctx, *params)
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+				params.release()
+			}
+			if err != nil {
+				if options.Retry.isRetryable(ctx, err) || ctx.Err() != nil {
+					return err
+				}
+				slog.Warn(fmt.Sprintf("Dropping spooled batch of %d bytes rejected by server: %v", len(record.body), err))
+			}
+		}
+		s.remove(seg)
+	}
+}
+
+// SpoolSize returns the size in bytes of the batches stored in the write spool.
+func (c *Client) SpoolSize() int64 {
+	if c.spool == nil {
+		return 0
+	}
+	return c.spool.bytes()
+}
+
+// spoolWrite stores a batch in the spool. cause is the error of the failed write, if any.
+func (c *Client) spoolWrite(buff []byte, options *WriteOptions, cause error) error {
+	database := options.Database
+	if database == "" {
+		database = c.config.Database
+	}
+	err := c.spool.append(spoolRecord{meta: newSpoolRecordMeta(database, options), body: buff})
+	if err != nil {
+		if cause != nil {
+			return fmt.Errorf("%w (spool: %w)", cause, err)
+		}
+		return err
+	}
+	if cause != nil {
+		slog.Debug(fmt.Sprintf("Write failed, %d bytes stored in the spool: %v", len(buff), cause))
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spoolTestServer records written lines and fails while down is set.
+type spoolTestServer struct {
+	*httptest.Server
+	down  atomic.Bool
+	mu    sync.Mutex
+	lines []string
+}
+
+func newSpoolTestServer(t *testing.T) *spoolTestServer {
+	s := &spoolTestServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		if s.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("db") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.lines = append(s.lines, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *spoolTestServer) written() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.lines...)
+}
+
+func newSpoolTestClient(t *testing.T, url string, spool SpoolOptions) *Client {
+	if spool.ReplayInterval == 0 {
+		spool.ReplayInterval = time.Hour
+	}
+	c, err := New(ClientConfig{
+		Host:     url,
+		Token:    "my-token",
+		Database: "my-database",
+		WriteOptions: &WriteOptions{
+			GzipThreshold: 0,
+			AcceptPartial: true,
+		},
+		Spool: &spool,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestSpoolStoresFailedWrites(t *testing.T) {
+	ts := newSpoolTestServer(t)
+	c := newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: t.TempDir()})
+	defer c.Close()
+
+	ts.down.Store(true)
+	require.NoError(t, c.Write(context.Background(), []byte("a f=1")))
+	assert.Positive(t, c.SpoolSize())
+
+	// pending batches are stored while spool is not empty
+	ts.down.Store(false)
+	require.NoError(t, c.WritePoints(context.Background(), []*Point{NewPointWithMeasurement("a").SetField("f", 2)}))
+	require.NoError(t, c.WriteData(context.Background(), []any{&struct {
+		Measurement string `lp:"measurement"`
+		F           int    `lp:"field,f"`
+	}{"a", 3}}))
+	assert.Empty(t, ts.written())
+
+	require.NoError(t, c.ReplaySpool(context.Background()))
+	assert.Equal(t, []string{"a f=1", "a f=2i", "a f=3i"}, ts.written())
+	assert.Equal(t, int64(0), c.SpoolSize())
+
+	// spool is empty, write directly
+	require.NoError(t, c.Write(context.Background(), []byte("a f=4")))
+	assert.Equal(t, []string{"a f=1", "a f=2i", "a f=3i", "a f=4"}, ts.written())
+}
+
+func TestSpoolReplayFailure(t *testing.T) {
+	ts := newSpoolTestServer(t)
+	c := newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: t.TempDir(), SegmentSize: 10})
+	defer c.Close()
+
+	ts.down.Store(true)
+	for _, lp := range []string{"a f=1", "a f=2", "a f=3"} {
+		require.NoError(t, c.Write(context.Background(), []byte(lp)))
+	}
+	err := c.ReplaySpool(context.Background())
+	var svErr *ServerError
+	require.ErrorAs(t, err, &svErr)
+	assert.Equal(t, http.StatusServiceUnavailable, svErr.StatusCode)
+	assert.Positive(t, c.SpoolSize())
+
+	ts.down.Store(false)
+	require.NoError(t, c.ReplaySpool(context.Background()))
+	assert.Equal(t, []string{"a f=1", "a f=2", "a f=3"}, ts.written())
+}
+
+func TestSpoolDoesNotStoreRejectedWrites(t *testing.T) {
+	ts := newSpoolTestServer(t)
+	c := newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: t.TempDir()})
+	defer c.Close()
+
+	err := c.Write(context.Background(), []byte("a f=1"), WithDatabase("bad"))
+	require.Error(t, err)
+	assert.Equal(t, int64(0), c.SpoolSize())
+}
+
+func TestSpoolDropsRejectedBatchesOnReplay(t *testing.T) {
+	ts := newSpoolTestServer(t)
+	c := newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: t.TempDir()})
+	defer c.Close()
+
+	ts.down.Store(true)
+	require.NoError(t, c.Write(context.Background(), []byte("a f=1"), WithDatabase("bad")))
+	require.NoError(t, c.Write(context.Background(), []byte("a f=2")))
+	ts.down.Store(false)
+	require.NoError(t, c.ReplaySpool(context.Background()))
+	assert.Equal(t, []string{"a f=2"}, ts.written())
+}
+
+func TestSpoolSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ts := newSpoolTestServer(t)
+	ts.down.Store(true)
+	c := newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: dir, SegmentSize: 10})
+	for _, lp := range []string{"a f=1", "a f=2", "a f=3"} {
+		require.NoError(t, c.Write(context.Background(), []byte(lp), WithPrecision(Second)))
+	}
+	require.NoError(t, c.Close())
+
+	ts.down.Store(false)
+	// replay starts in background on creation
+	c = newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: dir})
+	defer c.Close()
+	assert.Eventually(t, func() bool {
+		return c.SpoolSize() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"a f=1", "a f=2", "a f=3"}, ts.written())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSpoolCorruptedSegment(t *testing.T) {
+	dir := t.TempDir()
+	ts := newSpoolTestServer(t)
+	ts.down.Store(true)
+	c := newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: dir})
+	for _, lp := range []string{"a f=1", "a f=2"} {
+		require.NoError(t, c.Write(context.Background(), []byte(lp)))
+	}
+	require.NoError(t, c.Close())
+
+	// damage the body of the last record
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+spoolSegmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	data, err := os.ReadFile(segments[0])
+	require.NoError(t, err)
+	data[len(data)-1] = 'X'
+	require.NoError(t, os.WriteFile(segments[0], data, 0o600))
+
+	records, err := readSpoolSegment(segments[0])
+	require.ErrorContains(t, err, "checksum mismatch")
+	assert.Len(t, records, 1)
+
+	ts.down.Store(false)
+	c = newSpoolTestClient(t, ts.URL, SpoolOptions{Dir: dir})
+	defer c.Close()
+	require.NoError(t, c.ReplaySpool(context.Background()))
+	assert.Equal(t, []string{"a f=1"}, ts.written())
+}
+
+func TestSpoolCorruptedRecordLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "0"+spoolSegmentExt)
+	header := make([]byte, spoolRecordHeaderSize)
+	binary.BigEndian.PutUint32(header[0:4], math.MaxUint32)
+	binary.BigEndian.PutUint32(header[4:8], math.MaxUint32)
+	require.NoError(t, os.WriteFile(path, append(header, "a f=1"...), 0o600))
+
+	records, err := readSpoolSegment(path)
+	require.ErrorContains(t, err, "truncated record")
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Empty(t, records)
+}
+
+func TestSpoolDropPolicy(t *testing.T) {
+	record := spoolRecord{meta: spoolRecordMeta{Database: "db"}, body: []byte("a f=1")}
+
+	s, err := openSpool(SpoolOptions{Dir: t.TempDir(), MaxSize: 200, SegmentSize: 1})
+	require.NoError(t, err)
+	for range 10 {
+		require.NoError(t, s.append(record))
+	}
+	assert.LessOrEqual(t, s.bytes(), int64(200))
+	first := s.segments[0].seq
+	assert.Positive(t, first, "oldest segments should be dropped")
+	s.close()
+
+	s, err = openSpool(SpoolOptions{Dir: t.TempDir(), MaxSize: 200, SegmentSize: 1, DropPolicy: SpoolDropNewest})
+	require.NoError(t, err)
+	count := 0
+	for {
+		err = s.append(record)
+		if err != nil {
+			break
+		}
+		count++
+	}
+	require.ErrorIs(t, err, ErrSpoolFull)
+	assert.Equal(t, uint64(0), s.segments[0].seq)
+	assert.Len(t, s.segments, count)
+	s.close()
+
+	_, err = openSpool(SpoolOptions{})
+	assert.EqualError(t, err, "spool directory not specified")
+}
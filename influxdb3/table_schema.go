@@ -0,0 +1,234 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// ColumnRole is the role of a column in an InfluxDB table.
+type ColumnRole int
+
+const (
+	// ColumnRoleUnknown is a column without InfluxDB column type metadata.
+	ColumnRoleUnknown ColumnRole = iota
+	// ColumnRoleTag is a tag column.
+	ColumnRoleTag
+	// ColumnRoleField is a field column.
+	ColumnRoleField
+	// ColumnRoleTimestamp is the timestamp column.
+	ColumnRoleTimestamp
+)
+
+// String returns the name of the role.
+func (r ColumnRole) String() string {
+	switch r {
+	case ColumnRoleTag:
+		return "tag"
+	case ColumnRoleField:
+		return "field"
+	case ColumnRoleTimestamp:
+		return "timestamp"
+	default:
+		return "unknown"
+	}
+}
+
+// Column describes a column of a table.
+type Column struct {
+	// Name of the column.
+	Name string
+	// Type is the Arrow data type of the column.
+	Type arrow.DataType
+	// Role of the column, derived from the iox::column::type metadata.
+	Role ColumnRole
+}
+
+// TableSchema describes the columns of a table.
+type TableSchema struct {
+	// Name of the table.
+	Name string
+	// Columns in the order returned by the server.
+	Columns []Column
+}
+
+// Tags returns the names of the tag columns.
+func (s *TableSchema) Tags() []string {
+	return s.columnNames(ColumnRoleTag)
+}
+
+// Fields returns the names of the field columns.
+func (s *TableSchema) Fields() []string {
+	return s.columnNames(ColumnRoleField)
+}
+
+func (s *TableSchema) columnNames(role ColumnRole) []string {
+	var names []string
+	for _, c := range s.Columns {
+		if c.Role == role {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// ListTables returns the names of the tables (measurements) of a database.
+// The SQL information schema is queried by default, use WithQueryType(InfluxQL)
+// to use SHOW MEASUREMENTS instead.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - database: The database, the database of ClientConfig if empty.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - The names of the tables.
+//   - An error, if any.
+func (c *Client) ListTables(ctx context.Context, database string, options ...QueryOption) ([]string, error) {
+	opts := newQueryOptions(&DefaultQueryOptions, tableSchemaOptions(database, options))
+
+	query := "SELECT table_name FROM information_schema.tables WHERE table_schema = 'iox' ORDER BY table_name"
+	column := "table_name"
+	if opts.QueryType == InfluxQL {
+		query = "SHOW MEASUREMENTS"
+		column = "name"
+	}
+
+	it, err := c.query(ctx, query, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var tables []string
+	for it.Next() {
+		if name, ok := it.Value()[column].(string); ok {
+			tables = append(tables, name)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// DescribeTable returns the columns of a table with their Arrow type and role.
+// Only the schema of the table is read, no rows are returned by the server for SQL.
+// Use WithQueryType(InfluxQL) to describe the table with an InfluxQL query.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - database: The database, the database of ClientConfig if empty.
+//   - table: The name of the table.
+//   - options: The optional query options. See QueryOption for available options.
+//
+// Returns:
+//   - The table schema (*TableSchema).
+//   - An error, if any.
+func (c *Client) DescribeTable(ctx context.Context, database string, table string, options ...QueryOption) (*TableSchema, error) {
+	opts := newQueryOptions(&DefaultQueryOptions, tableSchemaOptions(database, options))
+
+	query := fmt.Sprintf("SELECT * FROM %s LIMIT 0", quoteSQLIdentifier(table))
+	if opts.QueryType == InfluxQL {
+		// InfluxQL treats LIMIT 0 as no limit
+		query = fmt.Sprintf("SELECT * FROM %s LIMIT 1", quoteInfluxQLIdentifier(table))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reader, err := c.getReader(ctx, query, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseReader(reader)
+
+	schema := reader.Schema()
+	if schema == nil {
+		// the schema of a Flight SQL result is known with the first record
+		reader.Next()
+		if err := reader.Err(); err != nil {
+			return nil, err
+		}
+		schema = reader.Schema()
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("no schema returned for table '%s'", table)
+	}
+
+	return newTableSchema(table, schema), nil
+}
+
+func tableSchemaOptions(database string, options []QueryOption) []QueryOption {
+	if database == "" {
+		return options
+	}
+	return append(options[:len(options):len(options)], WithDatabase(database))
+}
+
+func newTableSchema(table string, schema *arrow.Schema) *TableSchema {
+	s := &TableSchema{Name: table}
+	for _, f := range schema.Fields() {
+		// InfluxQL adds the measurement name as a column
+		if f.Name == "iox::measurement" {
+			continue
+		}
+		s.Columns = append(s.Columns, Column{
+			Name: f.Name,
+			Type: f.Type,
+			Role: columnRole(f),
+		})
+	}
+	return s
+}
+
+// columnRole returns the role of a column according to its metadata,
+// see getMetadataType for the metadata values.
+func columnRole(field arrow.Field) ColumnRole {
+	metadata, ok := field.Metadata.GetValue("iox::column::type")
+	switch {
+	case !ok:
+		if field.Name == "time" && field.Type.ID() == arrow.TIMESTAMP {
+			return ColumnRoleTimestamp
+		}
+		return ColumnRoleUnknown
+	case metadata == "iox::column_type::tag":
+		return ColumnRoleTag
+	case metadata == "iox::column_type::timestamp":
+		return ColumnRoleTimestamp
+	case strings.HasPrefix(metadata, "iox::column_type::field::"):
+		return ColumnRoleField
+	default:
+		return ColumnRoleUnknown
+	}
+}
+
+func quoteSQLIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteInfluxQLIdentifier(name string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
@@ -0,0 +1,141 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func columnTypeMetadata(columnType string) arrow.Metadata {
+	return arrow.NewMetadata([]string{"iox::column::type"}, []string{columnType})
+}
+
+var cpuTableSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "host", Type: arrow.BinaryTypes.String,
+		Metadata: columnTypeMetadata("iox::column_type::tag")},
+	{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond},
+		Metadata: columnTypeMetadata("iox::column_type::timestamp")},
+	{Name: "usage", Type: arrow.PrimitiveTypes.Float64, Nullable: true,
+		Metadata: columnTypeMetadata("iox::column_type::field::float")},
+}, nil)
+
+// fake Flight server returning table names or an empty result with the cpu table schema
+type schemaFlightServer struct {
+	flight.BaseFlightServer
+	mu      sync.Mutex
+	tickets []map[string]any
+}
+
+func (f *schemaFlightServer) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
+	var ticket map[string]any
+	if err := json.Unmarshal(tkt.GetTicket(), &ticket); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.tickets = append(f.tickets, ticket)
+	f.mu.Unlock()
+
+	if ticket["sql_query"] == "SHOW MEASUREMENTS" ||
+		ticket["sql_query"] == "SELECT table_name FROM information_schema.tables WHERE table_schema = 'iox' ORDER BY table_name" {
+		column := "table_name"
+		if ticket["query_type"] == "influxql" {
+			column = "name"
+		}
+		schema := arrow.NewSchema([]arrow.Field{{Name: column, Type: arrow.BinaryTypes.String}}, nil)
+		builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+		defer builder.Release()
+		builder.Field(0).(*array.StringBuilder).AppendValues([]string{"cpu", "mem"}, nil)
+		rec := builder.NewRecord()
+		defer rec.Release()
+		w := flight.NewRecordWriter(fs, ipc.WithSchema(schema))
+		return w.Write(rec)
+	}
+
+	w := flight.NewRecordWriter(fs, ipc.WithSchema(cpuTableSchema))
+	return w.Close()
+}
+
+func TestListTables(t *testing.T) {
+	f := &schemaFlightServer{}
+	client := startFlightServer(t, f, nil)
+
+	tables, err := client.ListTables(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpu", "mem"}, tables)
+
+	tables, err = client.ListTables(context.Background(), "other", WithQueryType(InfluxQL))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpu", "mem"}, tables)
+
+	require.Len(t, f.tickets, 2)
+	assert.Equal(t, "explore", f.tickets[0]["database"])
+	assert.Equal(t, "other", f.tickets[1]["database"])
+	assert.Equal(t, "SHOW MEASUREMENTS", f.tickets[1]["sql_query"])
+}
+
+func TestDescribeTable(t *testing.T) {
+	f := &schemaFlightServer{}
+	client := startFlightServer(t, f, nil)
+
+	schema, err := client.DescribeTable(context.Background(), "", `my"cpu`)
+	require.NoError(t, err)
+	assert.Equal(t, `my"cpu`, schema.Name)
+	require.Len(t, schema.Columns, 3)
+	assert.Equal(t, Column{Name: "usage", Type: arrow.PrimitiveTypes.Float64, Role: ColumnRoleField}, schema.Columns[2])
+	assert.Equal(t, ColumnRoleTimestamp, schema.Columns[1].Role)
+	assert.Equal(t, []string{"host"}, schema.Tags())
+	assert.Equal(t, []string{"usage"}, schema.Fields())
+
+	_, err = client.DescribeTable(context.Background(), "", `my"cpu`, WithQueryType(InfluxQL))
+	require.NoError(t, err)
+
+	require.Len(t, f.tickets, 2)
+	assert.Equal(t, `SELECT * FROM "my""cpu" LIMIT 0`, f.tickets[0]["sql_query"])
+	assert.Equal(t, `SELECT * FROM "my\"cpu" LIMIT 1`, f.tickets[1]["sql_query"])
+}
+
+func TestNewTableSchema(t *testing.T) {
+	schema := newTableSchema("m", arrow.NewSchema([]arrow.Field{
+		{Name: "iox::measurement", Type: arrow.BinaryTypes.String},
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+		{Name: "value", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "other", Type: arrow.PrimitiveTypes.Int64, Metadata: columnTypeMetadata("unknown")},
+	}, nil))
+	require.Len(t, schema.Columns, 3)
+	assert.Equal(t, ColumnRoleTimestamp, schema.Columns[0].Role)
+	assert.Equal(t, ColumnRoleUnknown, schema.Columns[1].Role)
+	assert.Equal(t, ColumnRoleUnknown, schema.Columns[2].Role)
+	assert.Equal(t, "unknown", schema.Columns[2].Role.String())
+	assert.Equal(t, "tag", ColumnRoleTag.String())
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/apache/arrow-go/v18/arrow/ipc"
 	"github.com/apache/arrow-go/v18/arrow/memory"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -108,6 +109,19 @@ func StartMockFlightServer(t *testing.T, blobSize int64) *flight.Server {
 	return &s
 }
 
+// StartFlightServer serves f on a local port until the test ends and returns the server address.
+func StartFlightServer(t *testing.T, f flight.FlightServer) string {
+	t.Helper()
+	s := flight.NewServerWithMiddleware(nil)
+	require.NoError(t, s.Init("localhost:0"))
+	s.RegisterFlightService(f)
+	go func() {
+		_ = s.Serve()
+	}()
+	t.Cleanup(s.Shutdown)
+	return s.Addr().String()
+}
+
 type BlobTicket struct {
 	Name string
 	Size int64
@@ -24,7 +24,6 @@ package influxdb3
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -101,15 +100,20 @@ func (c *Client) writePoints(ctx context.Context, points []*Point, options *Writ
 		tagOrder = c.config.WriteOptions.TagOrder
 	}
 
-	for _, p := range points {
+	// index of the point for each line
+	lineInputs := make([]int, 0, len(points))
+	for i, p := range points {
 		bts, err := p.marshalBinaryWithOptions(precision, defaultTags, tagOrder)
 		if err != nil {
 			return err
 		}
+		if len(bts) > 0 {
+			lineInputs = append(lineInputs, i)
+		}
 		buff = append(buff, bts...)
 	}
 
-	return c.write(ctx, buff, options)
+	return withFailedInputs(c.write(ctx, buff, options), lineInputs, points)
 }
 
 // Write writes line protocol record(s) to the server into the given database.
@@ -166,6 +170,7 @@ func (c *Client) makeHTTPParams(buff []byte, options *WriteOptions) (*httpParams
 	var precision = options.Precision
 
 	var gzipThreshold = options.GzipThreshold
+	var compression = options.Compression
 
 	var body io.Reader
 	var u *url.URL
@@ -190,21 +195,20 @@ func (c *Client) makeHTTPParams(buff []byte, options *WriteOptions) (*httpParams
 		}
 	}
 	u.RawQuery = params.Encode()
-	body = bytes.NewReader(buff)
 	headers := http.Header{"Content-Type": {"text/plain; charset=utf-8"}}
+	var pooled *pooledBody
 	if gzipThreshold > 0 && len(buff) >= gzipThreshold {
-		r, err := compressWithGzip(buff)
+		var err error
+		pooled, err = compression.compressBody(buff)
 		if err != nil {
 			return nil, fmt.Errorf("unable to compress body: %w", err)
 		}
-
+		headers["Content-Encoding"] = []string{compression.contentEncoding()}
+	} else {
 		// The request body must be replayable so NewRequest can set GetBody.
-		// compressWithGzip returns a replayable reader.
 		// This is particularly useful for transient HTTP/2 errors and persistent connections.
 		// Additionally, it helps manage graceful HTTP/2 shutdowns (e.g. GOAWAY frames).
-		body = r
-
-		headers["Content-Encoding"] = []string{"gzip"}
+		body = bytes.NewReader(buff)
 	}
 
 	return &httpParams{
@@ -213,6 +217,8 @@ func (c *Client) makeHTTPParams(buff []byte, options *WriteOptions) (*httpParams
 		headers:     headers,
 		queryParams: u.Query(),
 		body:        body,
+		pooledBody:  pooled,
+		size:        len(buff),
 	}, nil
 }
 
@@ -226,9 +232,22 @@ func (c *Client) write(ctx context.Context, buff []byte, options *WriteOptions)
 	if err != nil {
 		return err
 	}
+	defer params.release()
 
-	resp, err := c.makeAPICall(ctx, *params)
+	if c.spool != nil && !c.spool.empty() {
+		// Keep the order of data, the stored batches are written first
+		return c.spoolWrite(buff, options, nil)
+	}
+
+	resp, err := c.makeAPICallWithRetry(ctx, *params, &options.Retry)
 	if err != nil {
+		if c.spool != nil && options.Retry.isRetryable(ctx, err) {
+			return c.spoolWrite(buff, options, err)
+		}
+		var partialErr *PartialWriteError
+		if options.ResubmitValidLines && !options.AcceptPartial && errors.As(err, &partialErr) {
+			return c.resubmitValidLines(ctx, buff, options, partialErr)
+		}
 		var svErr *ServerError
 		if options.UseV2Api && errors.As(err, &svErr) && svErr.StatusCode == http.StatusMethodNotAllowed &&
 			strings.HasSuffix(params.endpointURL.Path, "/api/v2/write") {
@@ -253,6 +272,70 @@ func (c *Client) write(ctx context.Context, buff []byte, options *WriteOptions)
 	return resp.Body.Close()
 }
 
+// resubmitValidLines writes the lines of buff which were not rejected in partialErr.
+// It repeats until the server accepts the remaining lines, and returns a *PartialWriteError
+// holding all rejected lines numbered as in buff.
+func (c *Client) resubmitValidLines(ctx context.Context, buff []byte, options *WriteOptions, partialErr *PartialWriteError) error {
+	lines := bytes.SplitAfter(buff, []byte{'\n'})
+	if len(lines[len(lines)-1]) == 0 {
+		// buff ends with a newline
+		lines = lines[:len(lines)-1]
+	}
+	// index in lines for each line of the current payload
+	current := make([]int, len(lines))
+	for i := range current {
+		current[i] = i
+	}
+	result := *partialErr
+	result.LineErrors = nil
+
+	for {
+		failed := make(map[int]bool, len(partialErr.LineErrors))
+		for _, lineError := range partialErr.LineErrors {
+			if lineError.LineNumber < 1 || lineError.LineNumber > len(current) {
+				continue
+			}
+			failed[lineError.LineNumber-1] = true
+			lineError.LineNumber = current[lineError.LineNumber-1] + 1
+			result.LineErrors = append(result.LineErrors, lineError)
+		}
+		if len(failed) == 0 {
+			// cannot tell which lines are valid
+			return partialErr
+		}
+
+		var payload []byte
+		remaining := make([]int, 0, len(current)-len(failed))
+		for i, index := range current {
+			if !failed[i] {
+				payload = append(payload, lines[index]...)
+				remaining = append(remaining, index)
+			}
+		}
+		current = remaining
+		if len(bytes.TrimSpace(payload)) == 0 {
+			break
+		}
+
+		params, err := c.makeHTTPParams(payload, options)
+		if err != nil {
+			return err
+		}
+		resp, err := c.makeAPICallWithRetry(ctx, *params, &options.Retry)
+		params.release()
+		if err == nil {
+			_ = resp.Body.Close()
+			break
+		}
+		if !errors.As(err, &partialErr) {
+			return err
+		}
+	}
+
+	result.Message = partialWriteMessage(result.summary, result.LineErrors)
+	return &result
+}
+
 // WriteData encodes fields of custom points into line protocol
 // and writes line protocol record(s) to the server into the given database.
 // Each custom point must be annotated with 'lp' prefix and Values measurement, tag, field, or timestamp.
@@ -301,15 +384,20 @@ func (c *Client) WriteDataWithOptions(ctx context.Context, options *WriteOptions
 
 func (c *Client) writeData(ctx context.Context, points []any, options *WriteOptions) error {
 	var buff []byte
-	for _, p := range points {
+	// index of the point for each line
+	lineInputs := make([]int, 0, len(points))
+	for i, p := range points {
 		b, err := encode(p, options)
 		if err != nil {
 			return fmt.Errorf("error encoding point: %w", err)
 		}
+		if len(b) > 0 {
+			lineInputs = append(lineInputs, i)
+		}
 		buff = append(buff, b...)
 	}
 
-	return c.write(ctx, buff, options)
+	return withFailedInputs(c.write(ctx, buff, options), lineInputs, points)
 }
 
 func encode(x any, options *WriteOptions) ([]byte, error) {
@@ -416,17 +504,3 @@ func toV3PrecisionString(precision Precision) string {
 	}
 	panic(fmt.Errorf("unknown precision value %d", precision))
 }
-
-// compressWithGzip compresses data and returns it as a replayable bytes.Reader.
-func compressWithGzip(data []byte) (*bytes.Reader, error) {
-	var compressed bytes.Buffer
-	gzipWriter := gzip.NewWriter(&compressed)
-	if _, err := gzipWriter.Write(data); err != nil {
-		_ = gzipWriter.Close()
-		return nil, err
-	}
-	if err := gzipWriter.Close(); err != nil {
-		return nil, err
-	}
-	return bytes.NewReader(compressed.Bytes()), nil
-}
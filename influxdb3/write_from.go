@@ -0,0 +1,224 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// defaultWriteChunkSize specifies the default value of WriteOptions.ChunkSize.
+const defaultWriteChunkSize = 5 << 20
+
+// ErrChunkCanceled is the error of a chunk whose write was canceled because another chunk failed.
+// The server may have stored a part of such chunk.
+var ErrChunkCanceled = errors.New("chunk write canceled after another chunk failed")
+
+// WriteProgress reports the progress of WriteFrom.
+type WriteProgress struct {
+	// Chunks is the number of chunks written so far.
+	Chunks int
+	// Bytes is the number of bytes of the stream written so far.
+	Bytes int64
+}
+
+// ChunkWriteError describes a chunk of the stream passed to WriteFrom which failed to be written.
+type ChunkWriteError struct {
+	// Offset is the byte offset of the first line of the chunk in the stream.
+	Offset int64
+	// Size is the size of the chunk in bytes.
+	Size int
+	// Err is the write error.
+	Err error
+}
+
+// Error implements Error interface
+func (e *ChunkWriteError) Error() string {
+	return fmt.Sprintf("chunk at offset %d (%d bytes): %v", e.Offset, e.Size, e.Err)
+}
+
+// Unwrap returns the write error
+func (e *ChunkWriteError) Unwrap() error {
+	return e.Err
+}
+
+// StreamWriteError is returned by WriteFrom when some chunks failed to be written.
+type StreamWriteError struct {
+	// Chunks holds the failed chunks ordered by offset. Chunks interrupted by the failure
+	// of another chunk are included with ErrChunkCanceled.
+	Chunks []*ChunkWriteError
+}
+
+// Error implements Error interface
+func (e *StreamWriteError) Error() string {
+	msgs := make([]string, 0, len(e.Chunks))
+	for _, c := range e.Chunks {
+		msgs = append(msgs, c.Error())
+	}
+	return "write from stream failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the failed chunks
+func (e *StreamWriteError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Chunks))
+	for _, c := range e.Chunks {
+		errs = append(errs, c)
+	}
+	return errs
+}
+
+// WriteFrom reads line protocol records from r and writes them to the server into the given database.
+// Multiple records must be separated by the new line character (\n).
+//
+// The stream is split on line boundaries into chunks of at most WriteOptions.ChunkSize bytes,
+// which are written one after another, or concurrently when WriteOptions.ChunkConcurrency is greater than 1.
+// A line larger than ChunkSize is written as a single chunk. Each chunk is compressed according
+// to GzipThreshold, so memory usage is bounded by ChunkSize and ChunkConcurrency regardless of the stream size.
+//
+// Reading stops after the first failed chunk and the writes of the other chunks in flight are canceled.
+// The returned *StreamWriteError holds the byte offset of each failed or canceled chunk, which can be used
+// to resume the import.
+//
+// Parameters:
+//   - ctx: The context.Context to use for the request.
+//   - r: The line protocol stream.
+//   - options: Optional write options. See WriteOption for available options.
+//
+// Returns:
+//   - An error, if any.
+func (c *Client) WriteFrom(ctx context.Context, r io.Reader, options ...WriteOption) error {
+	return c.writeFrom(ctx, r, newWriteOptions(c.config.WriteOptions, options))
+}
+
+func (c *Client) writeFrom(parent context.Context, r io.Reader, options *WriteOptions) error {
+	chunkSize := options.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultWriteChunkSize
+	}
+	concurrency := max(options.ChunkConcurrency, 1)
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failed   []*ChunkWriteError
+		progress WriteProgress
+	)
+	sem := make(chan struct{}, concurrency)
+	send := func(chunk []byte, offset int64) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+		if ctx.Err() != nil {
+			<-sem
+			return false
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			err := c.write(ctx, chunk, options)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if errors.Is(err, context.Canceled) && parent.Err() == nil {
+					// canceled by the failure of another chunk
+					err = ErrChunkCanceled
+				}
+				failed = append(failed, &ChunkWriteError{Offset: offset, Size: len(chunk), Err: err})
+				cancel()
+				return
+			}
+			progress.Chunks++
+			progress.Bytes += int64(len(chunk))
+			if options.ChunkProgress != nil {
+				options.ChunkProgress(progress)
+			}
+		})
+		return true
+	}
+
+	br := bufio.NewReader(r)
+	var (
+		chunk  = make([]byte, 0, chunkSize)
+		line   []byte
+		offset int64
+		err    error
+	)
+	for {
+		line, err = readLine(br, line[:0])
+		if len(chunk) > 0 && len(chunk)+len(line) > chunkSize {
+			if !send(chunk, offset) {
+				break
+			}
+			offset += int64(len(chunk))
+			chunk = make([]byte, 0, chunkSize)
+		}
+		chunk = append(chunk, line...)
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(chunk) > 0 {
+				send(chunk, offset)
+			}
+			break
+		}
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sortChunkWriteErrors(failed)
+		return &StreamWriteError{Chunks: failed}
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read at offset %d: %w", offset+int64(len(chunk)), err)
+	}
+	return parent.Err()
+}
+
+// readLine appends the next line including the trailing '\n' to buf.
+func readLine(r *bufio.Reader, buf []byte) ([]byte, error) {
+	for {
+		line, err := r.ReadSlice('\n')
+		buf = append(buf, line...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return buf, err
+		}
+	}
+}
+
+func sortChunkWriteErrors(errs []*ChunkWriteError) {
+	slices.SortFunc(errs, func(a, b *ChunkWriteError) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+}
@@ -0,0 +1,236 @@
+/*
+ The MIT License
+
+ Permission is hereby granted, free of charge, to any person obtaining a copy
+ of this software and associated documentation files (the "Software"), to deal
+ in the Software without restriction, including without limitation the rights
+ to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ copies of the Software, and to permit persons to whom the Software is
+ furnished to do so, subject to the following conditions:
+
+ The above copyright notice and this permission notice shall be included in
+ all copies or substantial portions of the Software.
+
+ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ THE SOFTWARE.
+*/
+
+package influxdb3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkServer records request bodies and rejects those containing "bad".
+type chunkServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []string
+}
+
+func newChunkServer(t *testing.T) *chunkServer {
+	s := &chunkServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			body, _ = gzip.NewReader(body)
+		}
+		b, err := io.ReadAll(body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if bytes.Contains(b, []byte("slow")) {
+			<-r.Context().Done()
+			return
+		}
+		if bytes.Contains(b, []byte("bad")) {
+			returnHTTPError(w, http.StatusBadRequest, "bad chunk")
+			return
+		}
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(b))
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *chunkServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.bodies)
+}
+
+func lpLines(count int) []string {
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("m,t=%d f=%di\n", i%7, i)
+	}
+	return lines
+}
+
+func TestWriteFrom(t *testing.T) {
+	ts := newChunkServer(t)
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	lines := lpLines(100)
+	input := strings.Join(lines, "")
+	var progress []WriteProgress
+	err = c.WriteFrom(context.Background(), strings.NewReader(input),
+		WithChunkSize(64),
+		WithGzipThreshold(32),
+		WithChunkProgress(func(p WriteProgress) {
+			progress = append(progress, p)
+		}))
+	require.NoError(t, err)
+
+	bodies := ts.received()
+	assert.Greater(t, len(bodies), 1)
+	for _, b := range bodies {
+		assert.LessOrEqual(t, len(b), 64)
+		assert.True(t, strings.HasSuffix(b, "\n"))
+	}
+	assert.Equal(t, input, strings.Join(bodies, ""))
+	require.Len(t, progress, len(bodies))
+	assert.Equal(t, WriteProgress{Chunks: len(bodies), Bytes: int64(len(input))}, progress[len(progress)-1])
+}
+
+func TestWriteFromConcurrent(t *testing.T) {
+	ts := newChunkServer(t)
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	lines := lpLines(1000)
+	err = c.WriteFrom(context.Background(), strings.NewReader(strings.Join(lines, "")),
+		WithChunkSize(256),
+		WithChunkConcurrency(4))
+	require.NoError(t, err)
+
+	var received []string
+	for _, b := range ts.received() {
+		received = append(received, strings.SplitAfter(b, "\n")...)
+	}
+	received = slices.DeleteFunc(received, func(s string) bool { return s == "" })
+	slices.Sort(received)
+	slices.Sort(lines)
+	assert.Equal(t, lines, received)
+}
+
+func TestWriteFromLongLineAndNoTrailingNewline(t *testing.T) {
+	ts := newChunkServer(t)
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	long := "m f=\"" + strings.Repeat("x", 100) + "\"\n"
+	err = c.WriteFrom(context.Background(), strings.NewReader("m f=1\n"+long+"m f=2"),
+		WithChunkSize(16))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m f=1\n", long, "m f=2"}, ts.received())
+}
+
+func TestWriteFromChunkError(t *testing.T) {
+	ts := newChunkServer(t)
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	input := "m f=1\nm f=2\nbad f=3\nm f=4\nm f=5\n"
+	err = c.WriteFrom(context.Background(), strings.NewReader(input), WithChunkSize(12))
+	var streamErr *StreamWriteError
+	require.ErrorAs(t, err, &streamErr)
+	require.Len(t, streamErr.Chunks, 1)
+	assert.Equal(t, int64(12), streamErr.Chunks[0].Offset)
+	assert.Equal(t, 8, streamErr.Chunks[0].Size)
+	var svErr *ServerError
+	require.ErrorAs(t, err, &svErr)
+	assert.Equal(t, http.StatusBadRequest, svErr.StatusCode)
+	assert.Equal(t, []string{"m f=1\nm f=2\n"}, ts.received())
+}
+
+func TestWriteFromChunkErrorCancelsInFlightChunks(t *testing.T) {
+	ts := newChunkServer(t)
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	input := "slow f=1\nbad f=2\n"
+	err = c.WriteFrom(context.Background(), strings.NewReader(input), WithChunkSize(10), WithChunkConcurrency(2))
+	var streamErr *StreamWriteError
+	require.ErrorAs(t, err, &streamErr)
+	require.Len(t, streamErr.Chunks, 2)
+	assert.Equal(t, int64(0), streamErr.Chunks[0].Offset)
+	require.ErrorIs(t, streamErr.Chunks[0], ErrChunkCanceled)
+	assert.Equal(t, int64(9), streamErr.Chunks[1].Offset)
+	var svErr *ServerError
+	require.ErrorAs(t, streamErr.Chunks[1], &svErr)
+	assert.Equal(t, http.StatusBadRequest, svErr.StatusCode)
+	assert.NotErrorIs(t, err, context.Canceled)
+}
+
+type failingReader struct {
+	data io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, errors.New("disk error")
+	}
+	return n, err
+}
+
+func TestWriteFromReadError(t *testing.T) {
+	ts := newChunkServer(t)
+	c, err := New(ClientConfig{
+		Host:     ts.URL,
+		Token:    "my-token",
+		Database: "my-database",
+	})
+	require.NoError(t, err)
+
+	err = c.WriteFrom(context.Background(), &failingReader{data: strings.NewReader("m f=1\nm f=2\n")})
+	assert.EqualError(t, err, "read at offset 12: disk error")
+}
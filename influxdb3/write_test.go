@@ -1469,3 +1469,172 @@ func TestToV3PrecisionString(t *testing.T) {
 		toV3PrecisionString(5)
 	})
 }
+
+// partialWriteServer rejects lines containing "bad" like InfluxDB 3 Core/Enterprise with accept_partial=false.
+// When firstOnly is set, only the first invalid line is reported.
+func partialWriteServer(t *testing.T, firstOnly bool, written *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		assert.Equal(t, "false", r.URL.Query().Get("accept_partial"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+		var lineErrors []string
+		for i, line := range lines {
+			if strings.Contains(line, "bad") {
+				lineErrors = append(lineErrors, fmt.Sprintf(
+					`{"error_message":"invalid line","line_number":%d,"original_line":%q}`, i+1, line))
+				if firstOnly {
+					break
+				}
+			}
+		}
+		if len(lineErrors) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, `{"error":"parsing failed for write_lp endpoint","data":[%s]}`, strings.Join(lineErrors, ","))
+			return
+		}
+		mu.Lock()
+		*written = append(*written, lines...)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func newPartialWriteTestClient(t *testing.T, url string) *Client {
+	c, err := New(ClientConfig{
+		Host:     url,
+		Token:    "my-token",
+		Database: "my-database",
+		WriteOptions: &WriteOptions{
+			AcceptPartial: false,
+			UseV2Api:      false,
+		},
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestWritePointsPartialWriteFailedInputs(t *testing.T) {
+	var written []string
+	ts := partialWriteServer(t, false, &written)
+	defer ts.Close()
+	c := newPartialWriteTestClient(t, ts.URL)
+
+	points := []*Point{
+		NewPointWithMeasurement("ok").SetField("f", 1.0),
+		NewPointWithMeasurement("skipped").SetField("f", nil),
+		NewPointWithMeasurement("bad").SetField("f", 2.0),
+		NewPointWithMeasurement("ok").SetField("f", 3.0),
+		NewPointWithMeasurement("bad").SetField("f", 4.0),
+	}
+	err := c.WritePoints(context.Background(), points)
+	partialErr, ok := err.(*PartialWriteError) //nolint:errorlint
+	require.True(t, ok)
+	assert.Len(t, partialErr.LineErrors, 2)
+	require.Len(t, partialErr.FailedInputs, 2)
+	assert.Equal(t, 2, partialErr.FailedInputs[0].Index)
+	assert.Same(t, points[2], partialErr.FailedInputs[0].Input)
+	assert.Equal(t, 3, partialErr.FailedInputs[0].LineError.LineNumber)
+	assert.Equal(t, 4, partialErr.FailedInputs[1].Index)
+	assert.Same(t, points[4], partialErr.FailedInputs[1].Input)
+
+	var svErr *ServerError
+	require.ErrorAs(t, err, &svErr)
+	assert.Equal(t, http.StatusBadRequest, svErr.StatusCode)
+	assert.Empty(t, written)
+}
+
+func TestWriteDataPartialWriteFailedInputs(t *testing.T) {
+	var written []string
+	ts := partialWriteServer(t, false, &written)
+	defer ts.Close()
+	c := newPartialWriteTestClient(t, ts.URL)
+
+	type data struct {
+		Measurement string  `lp:"measurement"`
+		Value       float64 `lp:"field,value"`
+	}
+	points := []any{data{"ok", 1}, &data{"bad", 2}}
+	err := c.WriteData(context.Background(), points)
+	var partialErr *PartialWriteError
+	require.ErrorAs(t, err, &partialErr)
+	require.Len(t, partialErr.FailedInputs, 1)
+	assert.Equal(t, 1, partialErr.FailedInputs[0].Index)
+	assert.Same(t, points[1], partialErr.FailedInputs[0].Input)
+}
+
+func TestWriteResubmitValidLines(t *testing.T) {
+	for _, firstOnly := range []bool{false, true} {
+		t.Run(fmt.Sprintf("firstOnly=%t", firstOnly), func(t *testing.T) {
+			var written []string
+			ts := partialWriteServer(t, firstOnly, &written)
+			defer ts.Close()
+			c := newPartialWriteTestClient(t, ts.URL)
+
+			points := []*Point{
+				NewPointWithMeasurement("ok").SetField("f", 1.0),
+				NewPointWithMeasurement("bad").SetField("f", 2.0),
+				NewPointWithMeasurement("skipped").SetField("f", nil),
+				NewPointWithMeasurement("ok").SetField("f", 3.0),
+				NewPointWithMeasurement("bad").SetField("f", 4.0),
+				NewPointWithMeasurement("ok").SetField("f", 5.0),
+			}
+			err := c.WritePoints(context.Background(), points, WithResubmitValidLines(true))
+			assert.Equal(t, []string{"ok f=1", "ok f=3", "ok f=5"}, written)
+
+			var partialErr *PartialWriteError
+			require.ErrorAs(t, err, &partialErr)
+			require.Len(t, partialErr.FailedInputs, 2)
+			assert.Same(t, points[1], partialErr.FailedInputs[0].Input)
+			assert.Equal(t, 2, partialErr.FailedInputs[0].LineError.LineNumber)
+			assert.Same(t, points[4], partialErr.FailedInputs[1].Input)
+			assert.Equal(t, 4, partialErr.FailedInputs[1].LineError.LineNumber)
+			assert.Equal(t, "parsing failed for write_lp endpoint:\n"+
+				"\tline 2: invalid line (bad f=2)\n"+
+				"\tline 4: invalid line (bad f=4)", partialErr.Error())
+		})
+	}
+}
+
+func TestWriteResubmitValidLinesAllInvalid(t *testing.T) {
+	var written []string
+	ts := partialWriteServer(t, false, &written)
+	defer ts.Close()
+	c := newPartialWriteTestClient(t, ts.URL)
+
+	err := c.Write(context.Background(), []byte("bad f=1\nbad f=2\n"), WithResubmitValidLines(true))
+	var partialErr *PartialWriteError
+	require.ErrorAs(t, err, &partialErr)
+	assert.Len(t, partialErr.LineErrors, 2)
+	assert.Empty(t, written)
+}
+
+func TestWriteResubmitValidLinesAfterLastLine(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// initialization of query client
+		if r.Method == "PRI" {
+			return
+		}
+		requests.Add(1)
+		// the line number points after the last line of the payload
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"parsing failed for write_lp endpoint",` +
+			`"data":[{"error_message":"invalid line","line_number":3}]}`))
+	}))
+	defer ts.Close()
+	c := newPartialWriteTestClient(t, ts.URL)
+
+	err := c.Write(context.Background(), []byte("ok f=1\nok f=2\n"), WithResubmitValidLines(true))
+	var partialErr *PartialWriteError
+	require.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, 3, partialErr.LineErrors[0].LineNumber)
+	assert.Equal(t, int32(1), requests.Load())
+}